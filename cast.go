@@ -1,5 +1,11 @@
 package fuego
 
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
 // SC is a typed Stream cast function from a non-parameterised Stream[Any] to a parameterised Stream[U].
 // SC receives a typed Stream[U].
 //
@@ -7,19 +13,13 @@ package fuego
 // See doc.go for more details.
 //
 // See C for A typed cast.
+//
+// SC panics when an element is not of type U. See OfType, CastE and CastOrReject for checked casts.
 func SC[U any](from Stream[Any], to Stream[U]) Stream[U] {
-	toCh := make(chan U, from.concurrency)
-	to.stream = toCh
-
-	go func() {
-		defer close(to.stream)
-
-		for f := range from.stream {
-			to.stream <- interface{}(f).(U)
-		}
-	}()
+	toStream := cast[U](from)
+	toStream.concurrency = to.concurrency
 
-	return to
+	return toStream
 }
 
 // C is a typed cast function from a non-parameterised Stream[Any] to a parameterised type Stream[U].
@@ -29,20 +29,10 @@ func SC[U any](from Stream[Any], to Stream[U]) Stream[U] {
 // See doc.go for more details.
 //
 // See SC for A Stream cast.
+//
+// C panics when an element is not of type U. See OfType, CastE and CastOrReject for checked casts.
 func C[U any](from Stream[Any], to U) Stream[U] {
-	toCh := make(chan U, from.concurrency)
-
-	toStream := NewConcurrentStream(toCh, from.concurrency)
-
-	go func() {
-		defer close(toStream.stream)
-
-		for f := range from.stream {
-			toStream.stream <- interface{}(f).(U)
-		}
-	}()
-
-	return toStream
+	return cast[U](from)
 }
 
 // CC is a typed cast function from a non-parameterised Stream[Any] to a parameterised type ComparableStream[U].
@@ -51,19 +41,7 @@ func C[U any](from Stream[Any], to U) Stream[U] {
 // CC exists to address the current lack of support in Go for parameterised methods and a performance issue with Go 1.18.
 // See doc.go for more details.
 func CC[U Comparable](from Stream[Any], to U) ComparableStream[U] {
-	toCh := make(chan U, from.concurrency)
-
-	toStream := NewConcurrentStream(toCh, from.concurrency)
-
-	go func() {
-		defer close(toStream.stream)
-
-		for f := range from.stream {
-			toStream.stream <- interface{}(f).(U)
-		}
-	}()
-
-	return ComparableStream[U]{toStream}
+	return ComparableStream[U]{cast[U](from)}
 }
 
 // MC is a typed cast function from a non-parameterised Stream[Any] to a parameterised type MathableStream[U].
@@ -72,17 +50,87 @@ func CC[U Comparable](from Stream[Any], to U) ComparableStream[U] {
 // MC exists to address the current lack of support in Go for parameterised methods and a performance issue with Go 1.18.
 // See doc.go for more details.
 func MC[U Mathable](from Stream[Any], to U) MathableStream[U] {
-	toCh := make(chan U, from.concurrency)
+	return MathableStream[U]{cast[U](from)}
+}
+
+// cast converts the elements of a Stream[Any] to type U.
+func cast[U any](from Stream[Any]) Stream[U] {
+	return derive(from, func(ctx context.Context, yield func(U) bool) {
+		from.run(ctx, func(f Any) bool {
+			return yield(interface{}(f).(U))
+		})
+	})
+}
+
+// CastError signifies that an element of a Stream[Any] is not of the type of a cast.
+type CastError struct {
+	Element Any          // offending element
+	Type    reflect.Type // dynamic type of the element
+	Target  reflect.Type // type of the cast
+}
+
+// Error implements the error interface.
+func (e *CastError) Error() string {
+	return fmt.Sprintf("cannot cast element '%v' of type %v to %v", e.Element, e.Type, e.Target)
+}
 
-	toStream := NewConcurrentStream(toCh, from.concurrency)
+// OfType is a checked cast from a Stream[Any] to a Stream[U] that drops the elements
+// that are not of type U.
+//
+// Unlike C, OfType never panics.
+func OfType[U any](from Stream[Any]) Stream[U] {
+	return derive(from, func(ctx context.Context, yield func(U) bool) {
+		from.run(ctx, func(f Any) bool {
+			u, ok := f.(U)
+			return !ok || yield(u)
+		})
+	})
+}
 
-	go func() {
-		defer close(toStream.stream)
+// CastE is a checked cast from a Stream[Any] to a Stream[U] that reports a *CastError
+// for each element that is not of type U.
+//
+// The errors are handled in accordance with the ErrorPolicy of the Stream, as with MapE.
+// The elements that caused an error are not published to the out-stream.
+func CastE[U any](from Stream[Any]) Stream[U] {
+	from = from.withErrorPropagation()
+
+	return derive(from, func(ctx context.Context, yield func(U) bool) {
+		errs := errorsOf(ctx)
+
+		from.run(ctx, func(f Any) bool {
+			u, ok := f.(U)
+			if !ok {
+				return errs.report(&CastError{
+					Element: f,
+					Type:    reflect.TypeOf(f),
+					Target:  reflect.TypeOf((*U)(nil)).Elem(),
+				})
+			}
+
+			return yield(u)
+		})
+	})
+}
 
-		for f := range from.stream {
-			toStream.stream <- interface{}(f).(U)
+// CastOrReject is a checked cast from a Stream[Any] to a Stream[U] that routes the elements
+// that are not of type U to a Stream of rejects.
+//
+// Both Streams share a single run of the pipeline of from, as with Partition: the buffer size
+// of the Streams and the BackPressure policy are set with WithBackPressure. An element routed
+// to a Stream whose terminal operation has completed is dropped.
+//
+// Under the default SpillWhenFull policy, the Streams can be consumed one after the other.
+// Under the SlowestGoverns policy, they must be consumed concurrently: consuming them one
+// after the other deadlocks once the buffer of the other Stream is full.
+func CastOrReject[U any](from Stream[Any]) (Stream[U], Stream[Any]) {
+	streams := fanOut(from, 2, func(f Any) int {
+		if _, ok := f.(U); ok {
+			return 0
 		}
-	}()
 
-	return MathableStream[U]{toStream}
+		return 1
+	})
+
+	return cast[U](streams[0]), streams[1]
 }
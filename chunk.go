@@ -0,0 +1,100 @@
+package fuego
+
+import "context"
+
+// Chunk returns a Stream of the elements of s grouped in consecutive slices of n elements.
+// The last slice holds the remaining elements and may be shorter.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if n < 1.
+func Chunk[T any](s Stream[T], n int) Stream[[]T] {
+	if n < 1 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, batches(s.run, n))
+}
+
+// Window returns a Stream of the windows of size consecutive elements of s, the start
+// of each window being step elements after the start of the previous window.
+//
+// With step < size, the windows overlap (sliding windows). With step == size, they are
+// contiguous (tumbling windows). With step > size, the elements in between the windows
+// are skipped. Only full windows are published: use Chunk to keep the trailing elements.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if size < 1 or step < 1.
+func Window[T any](s Stream[T], size, step int) Stream[[]T] {
+	if size < 1 || step < 1 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, func(ctx context.Context, yield func([]T) bool) {
+		window := make([]T, 0, size)
+		skip := 0
+
+		s.run(ctx, func(val T) bool {
+			if skip > 0 {
+				skip--
+				return true
+			}
+
+			if window = append(window, val); len(window) < size {
+				return true
+			}
+
+			if !yield(append([]T{}, window...)) {
+				return false
+			}
+
+			if step < size {
+				window = append(window[:0], window[step:]...)
+			} else {
+				window, skip = window[:0], step-size
+			}
+
+			return true
+		})
+	})
+}
+
+// ChunkBy returns a Stream of the runs of consecutive elements of s that have the same key.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func ChunkBy[T any, K comparable](s Stream[T], keyFn Function[T, K]) Stream[[]T] {
+	return derive(s, func(ctx context.Context, yield func([]T) bool) {
+		var (
+			chunk []T
+			key   K
+		)
+
+		open := true
+		errs := errorsOf(ctx)
+
+		s.run(ctx, func(val T) bool {
+			var k K
+			if err := try(func() { k = keyFn(val) }); err != nil {
+				return errs.report(err)
+			}
+
+			if len(chunk) > 0 && k != key {
+				if open = yield(chunk); !open {
+					return false
+				}
+
+				chunk = nil
+			}
+
+			chunk, key = append(chunk, val), k
+
+			return true
+		})
+
+		if open && len(chunk) > 0 {
+			yield(chunk)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package fuego
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk(t *testing.T) {
+	tt := map[string]struct {
+		stream Stream[int]
+		n      int
+		want   [][]int
+	}{
+		"Should return an empty Stream when nil channel": {
+			stream: Stream[int]{},
+			n:      2,
+			want:   [][]int{},
+		},
+		"Should return an empty Stream when empty": {
+			stream: NewStreamFromSlice([]int{}, 0),
+			n:      2,
+			want:   [][]int{},
+		},
+		"Should chunk with a final partial chunk": {
+			stream: NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0),
+			n:      2,
+			want:   [][]int{{1, 2}, {3, 4}, {5}},
+		},
+		"Should chunk a channel": {
+			stream: NewStream(func() chan int {
+				c := make(chan int, 3)
+				c <- 1
+				c <- 2
+				c <- 3
+				close(c)
+				return c
+			}()),
+			n:    3,
+			want: [][]int{{1, 2, 3}},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Chunk(tc.stream, tc.n).ToSlice())
+		})
+	}
+
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Chunk(NewStreamFromSlice([]int{1}, 0), 0) })
+}
+
+func TestChunk_Unbounded(t *testing.T) {
+	c := make(chan int)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case c <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	assert.Equal(t, [][]int{{0, 1}, {2, 3}}, Chunk(NewStream(c), 2).HeadN(2))
+}
+
+func TestWindow(t *testing.T) {
+	data := []int{1, 2, 3, 4, 5, 6, 7}
+
+	tt := map[string]struct {
+		size, step int
+		want       [][]int
+	}{
+		"Should slide the windows": {
+			size: 3,
+			step: 1,
+			want: [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}, {4, 5, 6}, {5, 6, 7}},
+		},
+		"Should slide the windows by step": {
+			size: 3,
+			step: 2,
+			want: [][]int{{1, 2, 3}, {3, 4, 5}, {5, 6, 7}},
+		},
+		"Should tumble the windows": {
+			size: 2,
+			step: 2,
+			want: [][]int{{1, 2}, {3, 4}, {5, 6}},
+		},
+		"Should skip the elements in between the windows": {
+			size: 2,
+			step: 3,
+			want: [][]int{{1, 2}, {4, 5}},
+		},
+		"Should return an empty Stream when shorter than a window": {
+			size: 8,
+			step: 1,
+			want: [][]int{},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Window(NewStreamFromSlice(data, 0), tc.size, tc.step).ToSlice())
+		})
+	}
+
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Window(NewStreamFromSlice(data, 0), 0, 1) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Window(NewStreamFromSlice(data, 0), 1, 0) })
+}
+
+func TestChunkBy(t *testing.T) {
+	tt := map[string]struct {
+		stream Stream[string]
+		want   [][]string
+	}{
+		"Should return an empty Stream when nil channel": {
+			stream: Stream[string]{},
+			want:   [][]string{},
+		},
+		"Should group the runs of consecutive elements with the same key": {
+			stream: NewStreamFromSlice([]string{"a", "b", "cc", "dd", "e", "ff"}, 0),
+			want:   [][]string{{"a", "b"}, {"cc", "dd"}, {"e"}, {"ff"}},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ChunkBy(tc.stream, func(s string) int { return len(s) }).ToSlice())
+		})
+	}
+
+	assert.Equal(t, [][]string{{"a", "b"}}, ChunkBy(NewStreamFromSlice([]string{"a", "b", "cc"}, 0), func(s string) int { return len(s) }).HeadN(1))
+}
@@ -0,0 +1,48 @@
+package fuego
+
+import "time"
+
+// Clock provides the time to the time-based operations of a Stream, such as BufferTime.
+//
+// The default Clock is the system clock. Another Clock can be set with Stream.WithClock,
+// for instance to test time-based pipelines deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a Timer that expires after duration d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event Timer created by a Clock. See time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the Timer expires.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from expiring. It returns false if the Timer has
+	// already expired or been stopped.
+	Stop() bool
+}
+
+// SystemClock is the Clock of the system. See package time.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer creates a Timer that expires after duration d.
+func (SystemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer is a Timer backed by a time.Timer.
+type systemTimer struct {
+	*time.Timer
+}
+
+// C returns the channel on which the time is delivered.
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -0,0 +1,130 @@
+package fuego
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock whose time only moves forward with advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+	created int // number of timers created
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.created++
+	c.cond.Broadcast()
+
+	return t
+}
+
+// advance moves the time forward by d and expires the timers that are due.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.timers[:0]
+
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+
+		t.c <- c.now
+	}
+
+	c.timers = pending
+	c.cond.Broadcast()
+}
+
+// blockUntil waits until n timers are pending.
+func (c *fakeClock) blockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) != n {
+		c.cond.Wait()
+	}
+}
+
+// blockUntilCreated waits until n timers were created.
+func (c *fakeClock) blockUntilCreated(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.created < n {
+		c.cond.Wait()
+	}
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			t.clock.cond.Broadcast()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestSystemClock(t *testing.T) {
+	clock := SystemClock{}
+
+	start := clock.Now()
+	timer := clock.NewTimer(time.Millisecond)
+
+	assert.False(t, (<-timer.C()).Before(start.Add(time.Millisecond)))
+	assert.False(t, timer.Stop())
+	assert.True(t, clock.NewTimer(time.Hour).Stop())
+}
+
+func TestStream_WithClock(t *testing.T) {
+	assert.Equal(t, SystemClock{}, NewStreamFromSlice([]int{}, 0).Clock())
+
+	clock := newFakeClock()
+	assert.Same(t, clock, NewStreamFromSlice([]int{}, 0).WithClock(clock).Filter(True[int]()).Clock())
+}
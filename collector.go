@@ -1,6 +1,10 @@
 package fuego
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"sync"
+)
 
 // NOTICE:
 // The code in this file was inspired by Java Collectors,
@@ -24,11 +28,15 @@ import "fmt"
 // Type T: type of input elements to the reduction operation
 // Type A: mutable accumulation type of the reduction operation (often hidden as an implementation detail)
 // Type R: result type of the reduction operation.
+//
+// A Collector may optionally have a combiner that merges two partial accumulations.
+// The combiner is what allows CollectParallel to split the accumulation across several
+// goroutines. See WithCombiner.
 type Collector[T, A, R any] struct {
 	supplier    Supplier[A]
 	accumulator BiFunction[A, T, A]
-	// combiner BiFunction[A]/BinaryOperator[A] // this is for joining parallel collectors
-	finisher Function[A, R]
+	combiner    BinaryOperator[A] // this is for joining parallel collectors
+	finisher    Function[A, R]
 }
 
 // NewCollector creates a new Collector.
@@ -52,6 +60,16 @@ func NewCollector[T, A, R any](supplier Supplier[A], accumulator BiFunction[A, T
 	}
 }
 
+// WithCombiner returns a copy of this Collector with the given combiner.
+//
+// The combiner merges two partial accumulations into one. It receives the partial
+// accumulations in the order of the goroutines that produced them and may
+// mutate and return the first one.
+func (c Collector[T, A, R]) WithCombiner(combiner BinaryOperator[A]) Collector[T, A, R] {
+	c.combiner = combiner
+	return c
+}
+
 // type MutationCollector func(Function, Collector) Collector
 // type Collecting func(MutationCollector) MutationCollector
 
@@ -91,7 +109,23 @@ func GroupingBy[T any, K comparable, A, D any](classifier Function[T, K], downst
 		return m
 	}
 
-	return NewCollector(supplier, accumulator, finisher)
+	var combiner BinaryOperator[map[K]A]
+
+	if downstream.combiner != nil {
+		combiner = func(m1, m2 map[K]A) map[K]A {
+			for k, v := range m2 {
+				if container, ok := m1[k]; ok {
+					v = downstream.combiner(container, v)
+				}
+
+				m1[k] = v
+			}
+
+			return m1
+		}
+	}
+
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(combiner)
 }
 
 // Mapping adapts a Collector with elements of type U to a collector with elements of type T.
@@ -104,7 +138,7 @@ func Mapping[T, U, A, R any](mapper Function[T, U], downstream Collector[U, A, R
 
 	finisher := downstream.finisher
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(downstream.combiner)
 }
 
 // FlatMapping adapts the Entries a Collector accepts to another type by
@@ -114,7 +148,8 @@ func FlatMapping[U, T, A, R any](mapper StreamFunction[T, U], collector Collecto
 
 	accumulator := func(supplierA A, entry T) A {
 		container := supplierA
-		stream := mapper(entry)
+		// the accumulator is not safe for concurrent use: the elements are consumed sequentially.
+		stream := mapper(entry).Concurrent(0)
 
 		stream.ForEach(
 			func(e U) {
@@ -126,7 +161,7 @@ func FlatMapping[U, T, A, R any](mapper StreamFunction[T, U], collector Collecto
 
 	finisher := collector.finisher
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(collector.combiner)
 }
 
 // Filtering filters the entries a Collector accepts to a subset that satisfy the given predicate.
@@ -143,7 +178,7 @@ func Filtering[T, A, R any](predicate Predicate[T], collector Collector[T, A, R]
 
 	finisher := collector.finisher
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(collector.combiner)
 }
 
 // Reducing returns a collector that performs a reduction of
@@ -161,13 +196,54 @@ func Reducing[T any](f2 BiFunction[T, T, T]) Collector[T, Optional[T], T] {
 		return OptionalOf(result)
 	}
 
+	combiner := func(o1, o2 Optional[T]) Optional[T] {
+		if !o1.IsPresent() {
+			return o2
+		}
+
+		if !o2.IsPresent() {
+			return o1
+		}
+
+		return OptionalOf(f2(o1.Get(), o2.Get()))
+	}
+
 	finisher := func(e Optional[T]) T {
 		// alternative:
 		// return e.OrElse(*new(T))
 		return e.Get()
 	}
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(combiner)
+}
+
+// Teeing returns a Collector that is the composite of two downstream Collectors.
+// Every element is processed by both downstream Collectors in a single pass, then their
+// results are merged into the final result with the merger function.
+//
+// Teeing has a combiner when both downstream Collectors have one.
+func Teeing[T, A1, R1, A2, R2, R any](downstream1 Collector[T, A1, R1], downstream2 Collector[T, A2, R2], merger BiFunction[R1, R2, R]) Collector[T, Tuple2[A1, A2], R] {
+	supplier := func() Tuple2[A1, A2] {
+		return NewTuple2(downstream1.supplier(), downstream2.supplier())
+	}
+
+	accumulator := func(supplierA Tuple2[A1, A2], entry T) Tuple2[A1, A2] {
+		return NewTuple2(downstream1.accumulator(supplierA.E1, entry), downstream2.accumulator(supplierA.E2, entry))
+	}
+
+	finisher := func(e Tuple2[A1, A2]) R {
+		return merger(downstream1.finisher(e.E1), downstream2.finisher(e.E2))
+	}
+
+	var combiner BinaryOperator[Tuple2[A1, A2]]
+
+	if downstream1.combiner != nil && downstream2.combiner != nil {
+		combiner = func(t1, t2 Tuple2[A1, A2]) Tuple2[A1, A2] {
+			return NewTuple2(downstream1.combiner(t1.E1, t2.E1), downstream2.combiner(t1.E2, t2.E2))
+		}
+	}
+
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(combiner)
 }
 
 // ToSlice returns a collector that accumulates the input entries into a Go slice.
@@ -181,9 +257,13 @@ func ToSlice[T any]() Collector[T, []T, []T] {
 		return append(supplier, element)
 	}
 
+	combiner := func(s1, s2 []T) []T {
+		return append(s1, s2...)
+	}
+
 	finisher := IdentityFinisher[[]T]
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(combiner)
 }
 
 // ToMap returns a collector that accumulates the input entries into a Go map.
@@ -204,12 +284,24 @@ func ToMap[T any, K comparable, V any](keyMapper Function[T, K], valueMapper Fun
 			return supplier
 		}
 
-		panic(fmt.Sprintf("%s: '%v'", PanicDuplicateKey, key))
+		panic(fmt.Errorf("%w: '%v'", PanicDuplicateKey, key))
+	}
+
+	combiner := func(m1, m2 map[K]V) map[K]V {
+		for key, value := range m2 {
+			if _, ok := m1[key]; ok {
+				panic(fmt.Errorf("%w: '%v'", PanicDuplicateKey, key))
+			}
+
+			m1[key] = value
+		}
+
+		return m1
 	}
 
 	finisher := IdentityFinisher[map[K]V]
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(combiner)
 }
 
 // ToMapWithMerge returns a collector that accumulates the input entries into a Go map.
@@ -236,9 +328,21 @@ func ToMapWithMerge[T any, K comparable, V any](keyMapper Function[T, K], valueM
 		return supplier
 	}
 
+	combiner := func(m1, m2 map[K]V) map[K]V {
+		for key, value := range m2 {
+			if existing, ok := m1[key]; ok {
+				value = mergeFn(existing, value)
+			}
+
+			m1[key] = value
+		}
+
+		return m1
+	}
+
 	finisher := IdentityFinisher[map[K]V]
 
-	return NewCollector(supplier, accumulator, finisher)
+	return NewCollector(supplier, accumulator, finisher).WithCombiner(combiner)
 }
 
 // IdentityFinisher is a basic finisher that returns the
@@ -252,16 +356,196 @@ func IdentityFinisher[T any](t T) T {
 // This is a continuous terminal operation and hence expects
 // the producer to close the stream in order to complete.
 func Collect[T, A, R any](s Stream[T], c Collector[T, A, R]) R {
-	if s.stream == nil {
+	s = s.start()
+
+	defer s.terminate()
+
+	return collect(s, c)
+}
+
+// collect reduces the stream with the supplied Collector.
+func collect[T, A, R any](s Stream[T], c Collector[T, A, R]) R {
+	if s.missingChannel() {
 		panic(PanicMissingChannel)
 	}
 
 	result := c.supplier()
-	for e := range s.stream {
-		result = c.accumulator(result, e)
+	errs := errorsOf(s.Context())
+
+	accumulate := func(e T) bool {
+		if err := try(func() { result = c.accumulator(result, e) }); err != nil {
+			return errs.report(err)
+		}
+
+		return true
 	}
 
+	if slice, ok := s.backing(); ok {
+		for _, e := range slice {
+			if !accumulate(e) {
+				break
+			}
+		}
+
+		return c.finisher(result)
+	}
+
+	s.each(accumulate)
+
 	finishedResult := c.finisher(result)
 
 	return finishedResult
 }
+
+// CollectParallel reduces and optionally mutates the stream with the supplied Collector,
+// splitting the accumulation across the stream's concurrency level of goroutines.
+//
+// Each goroutine accumulates the elements it reads from the stream in its own partial
+// accumulation, and the partial accumulations are merged with the Collector's combiner
+// before the finisher is applied. The order in which the elements are accumulated is
+// not defined: the Collector should not depend on it (e.g. ToSlice does not preserve
+// the order of the stream).
+//
+// CollectParallel falls back to Collect when the Collector has no combiner or when the
+// stream's concurrency level is less than 2.
+//
+// When the accumulation of a goroutine fails, e.g. because the Collector panics, the other
+// goroutines stop. Under a propagating error policy (see WithErrorPolicy), the failure is
+// reported as a *PanicError and the result combines the partial accumulations that did not fail.
+//
+// This is a continuous terminal operation and hence expects
+// the producer to close the stream in order to complete.
+func CollectParallel[T, A, R any](s Stream[T], c Collector[T, A, R]) R {
+	if c.combiner == nil || s.concurrency < 2 {
+		return Collect(s, c)
+	}
+
+	s = s.start()
+
+	defer s.terminate()
+
+	if s.missingChannel() {
+		panic(PanicMissingChannel)
+	}
+
+	ctx, cancel := context.WithCancel(s.Context())
+	defer cancel()
+
+	if slice, ok := s.backing(); ok {
+		// the slice is split in contiguous parts: no channel is involved.
+		return collectPartials(ctx, cancel, s.concurrency, c, func(i int, yield func(T) bool) {
+			for _, e := range slice[i*len(slice)/s.concurrency : (i+1)*len(slice)/s.concurrency] {
+				if !yield(e) {
+					return
+				}
+			}
+		})
+	}
+
+	// the pipeline of s is materialised once, by the branch taken: each run of it would
+	// consume the elements of a channel source.
+	var each func(ctx context.Context, yield func(T) bool)
+
+	if s.batch > 1 {
+		src := derive(s, batches(s.run, s.batch)).materialise(ctx)
+
+		each = func(ctx context.Context, yield func(T) bool) {
+			src.run(ctx, func(batch []T) bool {
+				for _, e := range batch {
+					if !yield(e) {
+						return false
+					}
+				}
+
+				return true
+			})
+		}
+	} else {
+		each = s.materialise(ctx).run
+	}
+
+	return collectPartials(ctx, cancel, s.concurrency, c, func(_ int, yield func(T) bool) {
+		each(ctx, yield)
+	})
+}
+
+// collectPartials accumulates n partial accumulations in parallel and combines them.
+// each publishes the elements of the i-th partial accumulation.
+//
+// A partial accumulation that fails, e.g. because the Collector panics, is reported to the
+// error sink of ctx and cancels the others with cancel. The partial accumulations that failed
+// are left out of the combination. The panics are re-raised unless the sink propagates errors.
+func collectPartials[T, A, R any](ctx context.Context, cancel context.CancelFunc, n int, c Collector[T, A, R], each func(i int, yield func(T) bool)) R {
+	errs := errorsOf(ctx)
+	done := ctx.Done()
+
+	accumulate := func(i int) A {
+		partial := c.supplier()
+
+		each(i, func(e T) bool {
+			partial = c.accumulator(partial, e)
+			return !cancelled(done)
+		})
+
+		return partial
+	}
+
+	partials := make([]A, n)
+	failed := make([]bool, n)
+
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+
+	for i := range partials {
+		go func(i int) {
+			defer wg.Done()
+
+			if err := try(func() { partials[i] = accumulate(i) }); err != nil {
+				failed[i] = true
+
+				errs.report(err)
+				cancel()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	errs.rethrow()
+
+	var (
+		result A
+		some   bool // result holds a partial accumulation
+	)
+
+	for i, partial := range partials {
+		switch {
+		case failed[i]:
+			continue
+		case !some:
+			result, some = partial, true
+		default:
+			result = c.combiner(result, partial)
+		}
+	}
+
+	if !some {
+		result = c.supplier()
+	}
+
+	return c.finisher(result)
+}
+
+// CollectE reduces and optionally mutates the stream with the supplied Collector
+// and returns the error(s) reported by the pipeline, if any (see Stream.Err).
+//
+// This is a continuous terminal operation and hence expects
+// the producer to close the stream in order to complete.
+func CollectE[T, A, R any](s Stream[T], c Collector[T, A, R]) (R, error) {
+	s = s.withErrorPropagation().start()
+
+	defer s.terminate()
+
+	result := collect(s, c)
+
+	return result, errorsOf(s.Context()).err()
+}
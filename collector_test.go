@@ -1,9 +1,12 @@
 package fuego
 
 import (
+	"errors"
 	"hash/crc32"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -74,7 +77,7 @@ func TestCollector_Collect_ToEntryMap(t *testing.T) {
 					name: "One",
 				},
 			},
-			expectedPanic: PanicDuplicateKey + ": 'One'",
+			expectedPanic: PanicDuplicateKey.Error() + ": 'One'",
 		},
 		"returns a map of employee (name, id)": {
 			inputData: getEmployeesSample(),
@@ -99,7 +102,15 @@ func TestCollector_Collect_ToEntryMap(t *testing.T) {
 			}
 
 			if tc.expectedPanic != "" {
-				assert.PanicsWithValue(t, tc.expectedPanic, func() { _ = employeeNameByID() })
+				defer func() {
+					pe, _ := recover().(*PanicError)
+					if assert.NotNil(t, pe, "the panics of the Collector are re-raised as a *PanicError") {
+						assert.EqualError(t, pe.Unwrap(), tc.expectedPanic)
+						assert.ErrorIs(t, pe, PanicDuplicateKey)
+					}
+				}()
+				_ = employeeNameByID()
+
 				return
 			}
 
@@ -327,3 +338,222 @@ func getEmployeesSample() []employee {
 		},
 	}
 }
+
+func TestCollector_CollectE(t *testing.T) {
+	errInvalid := errors.New("invalid number")
+
+	atoi := func(s string) (Any, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errInvalid
+		}
+		return i, nil
+	}
+
+	got, err := CollectE(
+		NewStreamFromSlice([]string{"1", "2", "x", "4"}, 0).
+			WithErrorPolicy(SkipErrors).
+			MapE(atoi),
+		ToSlice[Any]())
+	assert.Equal(t, []Any{1, 2, 4}, got)
+	assert.NoError(t, err)
+
+	got, err = CollectE(
+		NewStreamFromSlice([]string{"1", "2", "x", "4"}, 0).MapE(atoi),
+		ToSlice[Any]())
+	assert.Equal(t, []Any{1, 2}, got)
+	assert.ErrorIs(t, err, errInvalid)
+}
+
+func TestCollector_Teeing(t *testing.T) {
+	type stats struct {
+		total   float32
+		highest float32
+	}
+
+	salaryStats := Mapping(
+		employee.Salary,
+		Teeing(
+			Reducing(Sum[float32]),
+			Reducing(Max[float32]),
+			func(total, highest float32) stats { return stats{total: total, highest: highest} },
+		),
+	)
+
+	assert.Equal(t, stats{total: 10300, highest: 2500}, Collect(NewStreamFromSlice(getEmployeesSample(), 0), salaryStats))
+
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+
+	countAndSum := Teeing(
+		Mapping(func(int) int { return 1 }, Reducing(Sum[int])),
+		Reducing(Sum[int]),
+		NewTuple2[int, int],
+	)
+
+	assert.Equal(t, NewTuple2(1000, 499500), CollectParallel(NewStreamFromSlice(data, 0).Concurrent(4), countAndSum))
+}
+
+func TestCollector_CollectParallel(t *testing.T) {
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	t.Run("ToSlice", func(t *testing.T) {
+		got := CollectParallel(NewStreamFromSlice(data, 10).Concurrent(4), ToSlice[int]())
+		assert.ElementsMatch(t, data, got)
+	})
+
+	t.Run("GroupingBy Filtering Mapping Reducing", func(t *testing.T) {
+		got := CollectParallel(
+			NewStreamFromSlice(data, 10).Concurrent(4),
+			GroupingBy(
+				isEven,
+				Filtering(
+					intGreaterThanPredicate(9),
+					Mapping(
+						func(i int) int { return i * 2 },
+						Reducing(Sum[int]),
+					),
+				),
+			),
+		)
+		want := Collect(
+			NewStreamFromSlice(data, 10),
+			GroupingBy(
+				isEven,
+				Filtering(
+					intGreaterThanPredicate(9),
+					Mapping(
+						func(i int) int { return i * 2 },
+						Reducing(Sum[int]),
+					),
+				),
+			),
+		)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("FlatMapping", func(t *testing.T) {
+		got := CollectParallel(
+			NewStreamFromSlice([][]int{{1, 2}, {3}, {4, 5, 6}, {}}, 0).Concurrent(3),
+			FlatMapping(FlattenTypedSlice[int](0), ToSlice[int]()),
+		)
+		assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6}, got)
+	})
+
+	t.Run("ToMap", func(t *testing.T) {
+		got := CollectParallel(
+			NewStreamFromSlice(data, 10).Concurrent(4),
+			ToMap(Identity[int], isEven),
+		)
+		assert.Len(t, got, len(data))
+		assert.True(t, got[998])
+		assert.False(t, got[999])
+
+		assert.Panics(t, func() {
+			CollectParallel(
+				NewStreamFromSlice(data, 10).Concurrent(4),
+				ToMap(isEven, Identity[int]),
+			)
+		})
+	})
+
+	t.Run("Failing partial under a propagating policy", func(t *testing.T) {
+		// the slice is split in [1, 1] and [2, 3]: the first partial accumulation fails
+		s := FromSlice([]int{1, 1, 2, 3}).WithErrorPolicy(CollectErrors).Concurrent(2)
+
+		var got map[int]bool
+		assert.NotPanics(t, func() {
+			got = CollectParallel(s, ToMap(Identity[int], isEven))
+		})
+		// the failed partial accumulation is left out and stops the other one
+		assert.NotContains(t, got, 1)
+		for k, v := range got {
+			assert.Equal(t, isEven(k), v)
+		}
+
+		var pe *PanicError
+		assert.ErrorAs(t, s.Err(), &pe)
+	})
+
+	t.Run("Failing partial stops the others", func(t *testing.T) {
+		naturals := FromSeq(func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		})
+
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			assert.Panics(t, func() {
+				CollectParallel(naturals.Concurrent(4), ToMap(isEven, Identity[int]))
+			})
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the partial accumulations were not stopped")
+		}
+	})
+
+	t.Run("ToMapWithMerge", func(t *testing.T) {
+		got := CollectParallel(
+			NewStreamFromSlice(data, 10).Concurrent(4),
+			ToMapWithMerge(isEven, Identity[int], Sum[int]),
+		)
+		assert.Equal(t, map[bool]int{true: 249500, false: 250000}, got)
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		c := make(chan int, 10)
+		go func() {
+			defer close(c)
+			for _, i := range data {
+				c <- i
+			}
+		}()
+
+		got := CollectParallel(NewConcurrentStream(c, 4), ToSlice[int]())
+		assert.ElementsMatch(t, data, got)
+	})
+
+	t.Run("Batched", func(t *testing.T) {
+		got := CollectParallel(NewStreamFromSlice(data, 10).Filter(True[int]()).Concurrent(4).Batched(16), ToSlice[int]())
+		assert.ElementsMatch(t, data, got)
+	})
+
+	t.Run("Batched channel", func(t *testing.T) {
+		c := make(chan int, 10)
+		go func() {
+			defer close(c)
+			for _, i := range data {
+				c <- i
+			}
+		}()
+
+		got := CollectParallel(NewConcurrentStream(c, 4).Filter(True[int]()).Batched(16), ToSlice[int]())
+		assert.ElementsMatch(t, data, got)
+	})
+
+	t.Run("Collector without combiner", func(t *testing.T) {
+		count := NewCollector(
+			func() int { return 0 },
+			func(acc int, _ int) int { return acc + 1 },
+			IdentityFinisher[int],
+		)
+		assert.Nil(t, count.combiner)
+		assert.Equal(t, len(data), CollectParallel(NewStreamFromSlice(data, 10).Concurrent(4), count))
+		assert.Equal(t, len(data), CollectParallel(NewStreamFromSlice(data, 10).Concurrent(4), count.WithCombiner(Sum[int])))
+	})
+}
@@ -6,39 +6,36 @@ type ComparableStream[T Comparable] struct {
 }
 
 func (s ComparableStream[T]) Max() T {
-	if s.stream == nil {
-		panic(PanicMissingChannel)
-	}
-
-	val, ok := <-s.stream
-	if !ok {
-		panic(PanicNoSuchElement)
-	}
+	return s.reduce(Max[T])
+}
 
-	max := val
+// MaxOpt is the Optional counterpart of Max: it returns an empty Optional
+// rather than panicking when the channel is nil or the stream is empty.
+func (s ComparableStream[T]) MaxOpt() Optional[T] {
+	return s.LeftReduceOpt(Max[T])
+}
 
-	for val = range s.stream {
-		max = Max(max, val)
-	}
+func (s ComparableStream[T]) Min() T {
+	return s.reduce(Min[T])
+}
 
-	return max
+// MinOpt is the Optional counterpart of Min: it returns an empty Optional
+// rather than panicking when the channel is nil or the stream is empty.
+func (s ComparableStream[T]) MinOpt() Optional[T] {
+	return s.LeftReduceOpt(Min[T])
 }
 
-func (s ComparableStream[T]) Min() T {
-	if s.stream == nil {
+// reduce applies f2 to the elements of this Stream.
+// Panics if the channel is nil or the stream is empty.
+func (s ComparableStream[T]) reduce(f2 BiFunction[T, T, T]) T {
+	if s.missingChannel() {
 		panic(PanicMissingChannel)
 	}
 
-	val, ok := <-s.stream
+	res, ok := s.leftReduce(f2)
 	if !ok {
 		panic(PanicNoSuchElement)
 	}
 
-	min := val
-
-	for val = range s.stream {
-		min = Min(min, val)
-	}
-
-	return min
+	return res
 }
@@ -101,3 +101,15 @@ func TestComparableStream_Min(t *testing.T) {
 		})
 	}
 }
+
+func TestComparableStream_MaxOpt(t *testing.T) {
+	assert.Equal(t, OptionalEmpty[int](), CC(Stream[Any]{stream: nil}, Int).MaxOpt())
+	assert.Equal(t, OptionalEmpty[int](), CC(NewStreamFromSlice([]Any{}, 0), Int).MaxOpt())
+	assert.Equal(t, OptionalOf(7), CC(NewStreamFromSlice([]int{3, 7, 2}, 0).Map(ToAny[int]), Int).MaxOpt())
+}
+
+func TestComparableStream_MinOpt(t *testing.T) {
+	assert.Equal(t, OptionalEmpty[int](), CC(Stream[Any]{stream: nil}, Int).MinOpt())
+	assert.Equal(t, OptionalEmpty[int](), CC(NewStreamFromSlice([]Any{}, 0), Int).MinOpt())
+	assert.Equal(t, OptionalOf(2), CC(NewStreamFromSlice([]int{3, 7, 2}, 0).Map(ToAny[int]), Int).MinOpt())
+}
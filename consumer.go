@@ -4,6 +4,10 @@ package fuego
 // return any value.
 type Consumer[T any] func(T)
 
+// ConsumerE is a fallible Consumer that accepts one argument
+// and returns an error or nil.
+type ConsumerE[T any] func(T) error
+
 // BiConsumer that accepts two arguments and does not
 // return any value.
 type BiConsumer[T, U any] func(T, U)
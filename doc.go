@@ -18,6 +18,11 @@
 // A syntactically lighter approach is provided with `SC`` and `C``.
 // See functions `SC`` and `C `for casting Stream[Any] to a typed Stream[T any].
 //
+// Typed functions are also provided for the operations that change the type of the elements,
+// such as `Map`, `FlatMap`, `Fold`, `Zip` and `GroupBy`. `Pipe`, `Pipe2`, ... and `Then` keep
+// chains of typed stages readable left-to-right:
+//  Pipe2(stream, MapTo(f1), MapTo(f2)) instead of Map(Map(stream, f1), f2)
+//
 // Go 1.18 suffers from a performance issue:
 //
 package fuego
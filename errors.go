@@ -1,23 +1,304 @@
 package fuego
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Error is a sentinel error of fuego.
+//
+// The panics raised by the Stream operations carry an Error value (see the Panic* constants), which
+// can be tested with errors.Is after recovery:
+//
+//	defer func() {
+//		if err, ok := recover().(error); ok && errors.Is(err, PanicNoSuchElement) {
+//			...
+//		}
+//	}()
+//
+// The Optional-returning operations, such as FindFirst or ReduceOpt, do not panic on empty streams.
+type Error string
+
+// Error implements the error interface.
+func (e Error) Error() string {
+	return string(e)
+}
+
 // PanicMissingChannel signifies that the Stream is missing a channel.
-const PanicMissingChannel = "stream requires a channel"
+const PanicMissingChannel Error = "stream requires a channel"
 
 // PanicNoSuchElement signifies that the requested element is not present.
 // Examples: when the Stream is empty, or when an Optional does not have a value.
-const PanicNoSuchElement = "no such element"
+const PanicNoSuchElement Error = "no such element"
 
 // PanicCollectorMissingSupplier signifies that the Supplier of a Collector was not provided.
-const PanicCollectorMissingSupplier = "collector missing supplier"
+const PanicCollectorMissingSupplier Error = "collector missing supplier"
 
 // PanicCollectorMissingAccumulator signifies that the accumulator of a Collector was not provided.
-const PanicCollectorMissingAccumulator = "collector missing accumulator"
+const PanicCollectorMissingAccumulator Error = "collector missing accumulator"
 
 // PanicCollectorMissingFinisher signifies that the Finisher of a Collector was not provided.
-const PanicCollectorMissingFinisher = "collector missing finisher"
+const PanicCollectorMissingFinisher Error = "collector missing finisher"
 
 // PanicNilNotPermitted signifies that the `nil` value is not allowed in the context.
-const PanicNilNotPermitted = "nil not permitted"
+const PanicNilNotPermitted Error = "nil not permitted"
 
 // PanicDuplicateKey signifies that an attempt was made to duplicate a key in a container (such as a map).
-const PanicDuplicateKey = "duplicate key"
+// The panic value wraps PanicDuplicateKey with the offending key.
+const PanicDuplicateKey Error = "duplicate key"
+
+// PanicInvalidArgument signifies that an argument of an operation is out of its valid range.
+// Example: a chunk size that is not positive.
+const PanicInvalidArgument Error = "invalid argument"
+
+// ErrorPolicy determines how a Stream handles the errors returned by its fallible operations
+// such as MapE, FilterE or FlatMapE.
+type ErrorPolicy int
+
+const (
+	// FailFast stops the pipeline on the first error: all its stages, including those of the
+	// Streams it combines (e.g. with FlatMap, Concat or Merge), stop. This is the default policy.
+	FailFast ErrorPolicy = iota
+
+	// SkipErrors drops the elements that caused an error and carries on. The errors are discarded.
+	SkipErrors
+
+	// CollectErrors drops the elements that caused an error and carries on. All the errors are
+	// reported when the Stream completes.
+	CollectErrors
+)
+
+// PanicError holds a panic that was recovered from a function supplied to a Stream operation,
+// for instance a mapper run by a concurrent Map worker.
+//
+// Terminal operations re-raise the PanicError on their own goroutine. When the pipeline
+// propagates errors (see Stream.WithErrorPolicy), the PanicError is handled as an error instead.
+type PanicError struct {
+	Value any    // value passed to panic()
+	Stack []byte // stack trace of the goroutine that panicked
+}
+
+// newPanicError creates a PanicError from the recovered value r.
+// It must be called from the deferred function that recovered r to capture the original stack.
+// A *PanicError re-raised by another pipeline is returned as is.
+func newPanicError(r any) *PanicError {
+	if p, ok := r.(*PanicError); ok {
+		return p
+	}
+
+	return &PanicError{
+		Value: r,
+		Stack: debug.Stack(),
+	}
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// errorHandling holds the error settings of a Stream pipeline.
+//
+// The settings are immutable: switching the policy or the propagation of a Stream gives it a
+// copy of its errorHandling, so that the Streams it was derived from keep their own settings.
+type errorHandling struct {
+	policy    ErrorPolicy
+	propagate bool // panics are handled as errors rather than re-raised
+}
+
+// withPolicy returns a copy of h with the given policy, which switches the pipeline to error propagation.
+// h may be nil: the copy does not depend on the settings of h.
+func (h *errorHandling) withPolicy(policy ErrorPolicy) *errorHandling {
+	return &errorHandling{policy: policy, propagate: true}
+}
+
+// withPropagation returns h switched to error propagation, copying it if needed.
+// h may be nil.
+func (h *errorHandling) withPropagation() *errorHandling {
+	if h == nil {
+		return &errorHandling{propagate: true}
+	}
+
+	if h.propagate {
+		return h
+	}
+
+	return &errorHandling{policy: h.policy, propagate: true}
+}
+
+// sink creates an error sink with the settings of h.
+// h may be nil, in which case the sink has the default settings.
+func (h *errorHandling) sink() *errorSink {
+	if h == nil {
+		return &errorSink{}
+	}
+
+	return &errorSink{policy: h.policy, propagate: h.propagate}
+}
+
+// lastRun records the error sink of the last run of a Stream by a terminal operation (see Stream.Err).
+//
+// Each Stream created by a constructor or an intermediate operation has its own lastRun, which
+// the copies of the Stream with other settings (e.g. with Concurrent) share.
+type lastRun struct {
+	mu   sync.Mutex
+	sink *errorSink
+}
+
+// record records e as the error sink of the last run. r may be nil.
+func (r *lastRun) record(e *errorSink) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.sink = e
+	r.mu.Unlock()
+}
+
+// err returns the error(s) recorded by the last run, if any. r may be nil.
+func (r *lastRun) err() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	e := r.sink
+	r.mu.Unlock()
+
+	if e == nil {
+		return nil
+	}
+
+	return e.err()
+}
+
+// errorSink records the errors of a run of a Stream pipeline according to an ErrorPolicy.
+//
+// Each run of a pipeline by a terminal operation has its own sink, which is bound to the
+// context of the run: all the stages of the pipeline report to it (see errorsOf).
+type errorSink struct {
+	mu        sync.Mutex
+	policy    ErrorPolicy
+	propagate bool // panics are handled as errors rather than re-raised
+	errs      []error
+	panicked  *PanicError        // panic to re-raise in the terminal operation
+	cancel    context.CancelFunc // cancels the run when it must stop, nil if the sink is not bound to a run
+}
+
+// sinkKey is the context key of the error sink of a run.
+type sinkKey struct{}
+
+// withSink returns a copy of ctx bound to the error sink e.
+func withSink(ctx context.Context, e *errorSink) context.Context {
+	return context.WithValue(ctx, sinkKey{}, e)
+}
+
+// errorsOf returns the error sink bound to ctx. A sink with the default settings is returned
+// when ctx is not bound to one, i.e. when the pipeline is not run by a terminal operation.
+func errorsOf(ctx context.Context) *errorSink {
+	if e, ok := ctx.Value(sinkKey{}).(*errorSink); ok {
+		return e
+	}
+
+	return &errorSink{}
+}
+
+// report records err and returns whether the pipeline should carry on.
+//
+// A *PanicError is handled as any other error when the sink propagates errors. Otherwise,
+// it is kept for the terminal operation to re-raise and the pipeline stops.
+func (e *errorSink) report(err error) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if p, ok := err.(*PanicError); ok && !e.propagate {
+		if e.panicked == nil {
+			e.panicked = p
+		}
+
+		e.stop()
+
+		return false
+	}
+
+	switch e.policy {
+	case SkipErrors:
+		return true
+	case CollectErrors:
+		e.errs = append(e.errs, err)
+		return true
+	default:
+		if len(e.errs) == 0 {
+			e.errs = append(e.errs, err)
+		}
+		e.stop()
+		return false
+	}
+}
+
+// stop cancels the run the sink is bound to, such that all its stages stop.
+func (e *errorSink) stop() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// recoverPanic recovers a panic and records it with report.
+// It must be deferred directly.
+func (e *errorSink) recoverPanic() {
+	if r := recover(); r != nil {
+		e.report(newPanicError(r))
+	}
+}
+
+// merge reports the errors recorded by o, including its panic, to e.
+func (e *errorSink) merge(o *errorSink) {
+	o.mu.Lock()
+	errs := o.errs // o.errs is only ever appended to
+	panicked := o.panicked
+	o.mu.Unlock()
+
+	for _, err := range errs {
+		e.report(err)
+	}
+
+	if panicked != nil {
+		e.report(panicked)
+	}
+}
+
+// rethrow re-raises the recorded panic, if any.
+func (e *errorSink) rethrow() {
+	e.mu.Lock()
+	p := e.panicked
+	e.mu.Unlock()
+
+	if p != nil {
+		panic(p)
+	}
+}
+
+// err returns the error(s) recorded, if any.
+func (e *errorSink) err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.panicked != nil {
+		return errors.Join(append(e.errs[:len(e.errs):len(e.errs)], e.panicked)...)
+	}
+
+	return errors.Join(e.errs...)
+}
@@ -0,0 +1,500 @@
+package fuego
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"time"
+)
+
+// WindowResult is the result of the Collector of an event-time window. See WindowByEventTime.
+type WindowResult[K comparable, R any] struct {
+	Start time.Time // start of the window, inclusive
+	End   time.Time // end of the window, exclusive
+	Key   K         // key of the elements of the window
+	Value R         // result of the Collector over the elements of the window
+}
+
+// Windows determines how the elements of a Stream are assigned to event-time windows.
+// See TumblingWindows, SlidingWindows and SessionWindows.
+type Windows struct {
+	size  time.Duration
+	slide time.Duration
+	gap   time.Duration
+}
+
+// TumblingWindows assigns each element to a window of the given size. The windows
+// are contiguous and do not overlap. They are aligned on multiples of size since the
+// zero time (see time.Time.Truncate).
+// Panics if size <= 0.
+func TumblingWindows(size time.Duration) Windows {
+	return SlidingWindows(size, size)
+}
+
+// SlidingWindows assigns each element to the windows of the given size that contain it.
+// A new window starts every slide, such that the windows overlap when slide < size.
+// The windows are aligned on multiples of slide since the zero time (see time.Time.Truncate).
+// Panics if size <= 0 or slide <= 0.
+func SlidingWindows(size, slide time.Duration) Windows {
+	if size <= 0 || slide <= 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return Windows{size: size, slide: slide}
+}
+
+// SessionWindows assigns the elements of a key to sessions of activity. A session ends
+// after gap without elements: its window spans from its first element to its last element
+// plus gap. An element that bridges two sessions merges them: the elements of the merged
+// sessions are fed to the Collector session by session, starting with the largest one.
+// Panics if gap <= 0.
+func SessionWindows(gap time.Duration) Windows {
+	if gap <= 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return Windows{gap: gap}
+}
+
+// WatermarkStrategy returns the watermark of a Stream given the greatest event time seen so far.
+// The watermark asserts that no element with an earlier event time is expected anymore.
+type WatermarkStrategy func(maxEventTime time.Time) time.Time
+
+// BoundedOutOfOrderness returns a WatermarkStrategy for elements that arrive at most maxDelay
+// later than the elements with a greater event time. With maxDelay 0, the event time of the
+// elements is expected to be ascending.
+func BoundedOutOfOrderness(maxDelay time.Duration) WatermarkStrategy {
+	return func(maxEventTime time.Time) time.Time {
+		return maxEventTime.Add(-maxDelay)
+	}
+}
+
+// EventTime describes the event-time windowing of a Stream. See WindowByEventTime.
+type EventTime[T any, K comparable] struct {
+	// Timestamp returns the event time of an element. It is mandatory.
+	Timestamp Function[T, time.Time]
+
+	// Key returns the key of an element. The elements of different keys are assigned to
+	// different windows. When nil, all the elements have the zero key.
+	Key Function[T, K]
+
+	// Windows assigns the elements to windows. It is mandatory.
+	Windows Windows
+
+	// Watermark determines the watermark of the Stream. When nil, the event time of the
+	// elements is expected to be ascending (see BoundedOutOfOrderness).
+	Watermark WatermarkStrategy
+
+	// AllowedLateness is the time a window is kept after the watermark passed its end.
+	// A late element assigned to a window that is kept updates the result of the window,
+	// which is published again. Later elements are dropped.
+	AllowedLateness time.Duration
+}
+
+// WindowByEventTime returns a Stream of the results of the given Collector over the event-time
+// windows of the elements of s.
+//
+// Each element is assigned to windows according to its key and event time, as described by et.
+// The result of a window is published when the watermark passes the end of the window. The
+// results published at once are ordered by end then start of their windows. The remaining
+// windows are published when the in-stream is closed.
+//
+// The elements of a window are kept until the watermark passes the end of the window plus
+// the allowed lateness. They are fed to the Collector each time the result of the window is
+// published.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if et.Timestamp is nil or et.Windows is unset.
+func WindowByEventTime[T any, K comparable, A, R any](s Stream[T], et EventTime[T, K], c Collector[T, A, R]) Stream[WindowResult[K, R]] {
+	if et.Timestamp == nil {
+		panic(PanicNilNotPermitted)
+	}
+
+	if et.Windows == (Windows{}) {
+		panic(PanicInvalidArgument)
+	}
+
+	if et.Watermark == nil {
+		et.Watermark = BoundedOutOfOrderness(0)
+	}
+
+	return derive(s, func(ctx context.Context, yield func(WindowResult[K, R]) bool) {
+		w := newEventWindows(et)
+
+		collect := func(win *eventWindow[T, K]) WindowResult[K, R] {
+			acc := c.supplier()
+			for _, val := range win.elems {
+				acc = c.accumulator(acc, val)
+			}
+
+			return WindowResult[K, R]{Start: win.start, End: win.end, Key: win.key, Value: c.finisher(acc)}
+		}
+
+		errs := errorsOf(ctx)
+
+		publish := func(wins []*eventWindow[T, K]) bool {
+			for _, win := range wins {
+				var res WindowResult[K, R]
+				if err := try(func() { res = collect(win) }); err != nil {
+					if !errs.report(err) {
+						return false
+					}
+
+					continue
+				}
+
+				if !yield(res) {
+					return false
+				}
+			}
+
+			return true
+		}
+
+		open := true
+
+		s.run(ctx, func(val T) bool {
+			var wins []*eventWindow[T, K]
+			if err := try(func() { wins = w.add(val) }); err != nil {
+				open = errs.report(err)
+				return open
+			}
+
+			open = publish(wins)
+
+			return open
+		})
+
+		if open && !cancelled(ctx.Done()) {
+			publish(w.flush())
+		}
+	})
+}
+
+// eventWindow is a window of WindowByEventTime.
+type eventWindow[T any, K comparable] struct {
+	key   K
+	start time.Time
+	end   time.Time
+	elems []T
+	seq   int    // order of creation
+	fired bool   // the result of the window was published
+	pos   [2]int // positions of the window in the pending and kept heaps, -1 when not in
+}
+
+// eventWindowID identifies a window of fixed size by key and start.
+type eventWindowID[K comparable] struct {
+	key   K
+	start int64
+}
+
+// the heaps of eventWindows, which index the positions of a window (see eventWindow.pos).
+const (
+	pendingHeap = iota
+	keptHeap
+)
+
+// eventWindows holds the windows of WindowByEventTime.
+//
+// The windows are indexed by key (and start, for the windows of fixed size) to assign the
+// elements, and ordered by end in two heaps: the windows that did not fire yet, to fire them
+// as the watermark advances, and all the windows, to discard them once their allowed
+// lateness has elapsed.
+type eventWindows[T any, K comparable] struct {
+	et        EventTime[T, K]
+	index     map[eventWindowID[K]]*eventWindow[T, K] // windows of fixed size
+	sessions  map[K][]*eventWindow[T, K]              // session windows by key
+	pending   windowHeap[T, K]                        // windows that did not fire yet, by end
+	kept      windowHeap[T, K]                        // windows whose allowed lateness has not elapsed, by end
+	created   int                                     // number of windows created
+	maxTime   time.Time
+	watermark time.Time
+	started   bool // an element was received: the watermark is set
+}
+
+func newEventWindows[T any, K comparable](et EventTime[T, K]) *eventWindows[T, K] {
+	return &eventWindows[T, K]{
+		et:       et,
+		index:    map[eventWindowID[K]]*eventWindow[T, K]{},
+		sessions: map[K][]*eventWindow[T, K]{},
+		pending:  windowHeap[T, K]{slot: pendingHeap},
+		kept:     windowHeap[T, K]{slot: keptHeap},
+	}
+}
+
+// add assigns val to its windows and advances the watermark.
+// It returns the windows whose result must be published.
+func (w *eventWindows[T, K]) add(val T) []*eventWindow[T, K] {
+	ts := w.et.Timestamp(val)
+
+	var key K
+	if w.et.Key != nil {
+		key = w.et.Key(val)
+	}
+
+	var updated []*eventWindow[T, K]
+
+	if w.et.Windows.gap > 0 {
+		updated = w.addToSession(key, ts, val)
+	} else {
+		updated = w.addToFixed(key, ts, val)
+	}
+
+	// the late elements update the windows that already fired: they are published again,
+	// unless a session was extended beyond the watermark.
+	var due []*eventWindow[T, K]
+
+	for _, win := range updated {
+		if win.fired {
+			if w.watermark.Before(win.end) {
+				win.fired = false
+				heap.Push(&w.pending, win)
+
+				continue
+			}
+
+			due = append(due, win)
+		}
+	}
+
+	if !w.started || ts.After(w.maxTime) {
+		// the watermark never goes back
+		if wm := w.et.Watermark(ts); !w.started || wm.After(w.watermark) {
+			w.watermark = wm
+		}
+
+		w.maxTime, w.started = ts, true
+	}
+
+	return append(due, w.advance()...)
+}
+
+// addToFixed adds val to the tumbling or sliding windows that contain ts.
+func (w *eventWindows[T, K]) addToFixed(key K, ts time.Time, val T) []*eventWindow[T, K] {
+	size, slide := w.et.Windows.size, w.et.Windows.slide
+
+	var updated []*eventWindow[T, K]
+
+	for start := ts.Truncate(slide); start.Add(size).After(ts); start = start.Add(-slide) {
+		end := start.Add(size)
+		if w.expired(end) {
+			break
+		}
+
+		id := eventWindowID[K]{key: key, start: start.UnixNano()}
+
+		win, ok := w.index[id]
+		if !ok {
+			win = w.newWindow(key, start, end)
+			w.index[id] = win
+		}
+
+		win.elems = append(win.elems, val)
+		updated = append(updated, win)
+	}
+
+	return updated
+}
+
+// addToSession adds val to the session of key that contains ts, merging the sessions it bridges.
+//
+// The session is extended in place. When sessions are merged, the largest one absorbs the
+// elements of the others, which are discarded.
+func (w *eventWindows[T, K]) addToSession(key K, ts time.Time, val T) []*eventWindow[T, K] {
+	start, end := ts, ts.Add(w.et.Windows.gap)
+
+	var merged, sessions []*eventWindow[T, K]
+
+	for _, win := range w.sessions[key] {
+		if win.start.Before(end) && start.Before(win.end) {
+			start = minTime(start, win.start)
+			end = maxTime(end, win.end)
+			merged = append(merged, win)
+
+			continue
+		}
+
+		sessions = append(sessions, win)
+	}
+
+	if w.expired(end) {
+		return nil
+	}
+
+	if len(merged) == 0 {
+		session := w.newWindow(key, start, end)
+		session.elems = append(session.elems, val)
+		w.sessions[key] = append(sessions, session)
+
+		return []*eventWindow[T, K]{session}
+	}
+
+	session := slices.MaxFunc(merged, func(a, b *eventWindow[T, K]) int { return len(a.elems) - len(b.elems) })
+
+	for _, win := range merged {
+		if win == session {
+			continue
+		}
+
+		session.elems = append(session.elems, win.elems...)
+		session.fired = session.fired || win.fired
+		w.remove(win)
+	}
+
+	if i := session.pos[pendingHeap]; session.fired && i >= 0 {
+		// the session takes over the published result of the sessions it merged
+		heap.Remove(&w.pending, i)
+	}
+
+	session.start, session.end = start, end
+	session.elems = append(session.elems, val)
+	w.fix(session)
+
+	w.sessions[key] = append(sessions, session)
+
+	return []*eventWindow[T, K]{session}
+}
+
+// newWindow creates a window that did not fire yet.
+func (w *eventWindows[T, K]) newWindow(key K, start, end time.Time) *eventWindow[T, K] {
+	win := &eventWindow[T, K]{key: key, start: start, end: end, seq: w.created, pos: [2]int{-1, -1}}
+	w.created++
+
+	heap.Push(&w.pending, win)
+	heap.Push(&w.kept, win)
+
+	return win
+}
+
+// fix restores the order of the heaps that hold win after its start or end changed.
+func (w *eventWindows[T, K]) fix(win *eventWindow[T, K]) {
+	for _, h := range []*windowHeap[T, K]{&w.pending, &w.kept} {
+		if i := win.pos[h.slot]; i >= 0 {
+			heap.Fix(h, i)
+		}
+	}
+}
+
+// remove removes win from the heaps that hold it.
+func (w *eventWindows[T, K]) remove(win *eventWindow[T, K]) {
+	for _, h := range []*windowHeap[T, K]{&w.pending, &w.kept} {
+		if i := win.pos[h.slot]; i >= 0 {
+			heap.Remove(h, i)
+		}
+	}
+}
+
+// advance returns the windows whose end was passed by the watermark and that did not fire yet,
+// ordered by end then start, and discards the windows whose allowed lateness has elapsed.
+func (w *eventWindows[T, K]) advance() []*eventWindow[T, K] {
+	var due []*eventWindow[T, K]
+
+	for w.pending.Len() > 0 && !w.watermark.Before(w.pending.wins[0].end) {
+		win := heap.Pop(&w.pending).(*eventWindow[T, K])
+		win.fired = true
+		due = append(due, win)
+	}
+
+	for w.kept.Len() > 0 && w.expired(w.kept.wins[0].end) {
+		w.discard(heap.Pop(&w.kept).(*eventWindow[T, K]))
+	}
+
+	return due
+}
+
+// discard removes win from the index of its key.
+func (w *eventWindows[T, K]) discard(win *eventWindow[T, K]) {
+	if w.et.Windows.gap == 0 {
+		delete(w.index, eventWindowID[K]{key: win.key, start: win.start.UnixNano()})
+		return
+	}
+
+	sessions := slices.DeleteFunc(w.sessions[win.key], func(s *eventWindow[T, K]) bool { return s == win })
+	if len(sessions) == 0 {
+		delete(w.sessions, win.key)
+		return
+	}
+
+	w.sessions[win.key] = sessions
+}
+
+// flush returns the windows that did not fire yet, ordered by end then start, and discards all the windows.
+func (w *eventWindows[T, K]) flush() []*eventWindow[T, K] {
+	var due []*eventWindow[T, K]
+
+	for w.pending.Len() > 0 {
+		win := heap.Pop(&w.pending).(*eventWindow[T, K])
+		win.fired = true
+		due = append(due, win)
+	}
+
+	w.index, w.sessions, w.kept = nil, nil, windowHeap[T, K]{slot: keptHeap}
+
+	return due
+}
+
+// expired returns whether the allowed lateness of a window that ends at end has elapsed.
+func (w *eventWindows[T, K]) expired(end time.Time) bool {
+	return w.started && !w.watermark.Before(end.Add(w.et.AllowedLateness))
+}
+
+// windowHeap is a heap of windows ordered by end then start, then in order of creation.
+// It implements heap.Interface and records the positions of the windows in eventWindow.pos.
+type windowHeap[T any, K comparable] struct {
+	wins []*eventWindow[T, K]
+	slot int // index of the positions of the windows in this heap (see eventWindow.pos)
+}
+
+func (h *windowHeap[T, K]) Len() int { return len(h.wins) }
+
+func (h *windowHeap[T, K]) Less(i, j int) bool {
+	a, b := h.wins[i], h.wins[j]
+
+	if c := a.end.Compare(b.end); c != 0 {
+		return c < 0
+	}
+
+	if c := a.start.Compare(b.start); c != 0 {
+		return c < 0
+	}
+
+	return a.seq < b.seq
+}
+
+func (h *windowHeap[T, K]) Swap(i, j int) {
+	h.wins[i], h.wins[j] = h.wins[j], h.wins[i]
+	h.wins[i].pos[h.slot], h.wins[j].pos[h.slot] = i, j
+}
+
+func (h *windowHeap[T, K]) Push(x any) {
+	win := x.(*eventWindow[T, K])
+	win.pos[h.slot] = len(h.wins)
+	h.wins = append(h.wins, win)
+}
+
+func (h *windowHeap[T, K]) Pop() any {
+	n := len(h.wins) - 1
+	win := h.wins[n]
+	win.pos[h.slot] = -1
+	h.wins[n] = nil
+	h.wins = h.wins[:n]
+
+	return win
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
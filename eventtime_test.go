@@ -0,0 +1,240 @@
+package fuego
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	key string
+	at  int // seconds since epoch
+	val int
+}
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(seconds int) time.Time {
+	return epoch.Add(time.Duration(seconds) * time.Second)
+}
+
+func (e event) Key() string {
+	return e.key
+}
+
+func (e event) Timestamp() time.Time {
+	return at(e.at)
+}
+
+func (e event) Val() int {
+	return e.val
+}
+
+func TestWindowByEventTime(t *testing.T) {
+	vals := Mapping(event.Val, ToSlice[int]())
+
+	tt := map[string]struct {
+		events []event
+		et     EventTime[event, string]
+		want   []WindowResult[string, []int]
+	}{
+		"Should publish the tumbling windows by key when the watermark passes their end": {
+			events: []event{{"a", 1, 1}, {"b", 2, 2}, {"a", 5, 3}, {"a", 11, 4}, {"b", 12, 5}, {"a", 25, 6}},
+			et: EventTime[event, string]{
+				Timestamp: event.Timestamp,
+				Key:       event.Key,
+				Windows:   TumblingWindows(10 * time.Second),
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(0), End: at(10), Key: "a", Value: []int{1, 3}},
+				{Start: at(0), End: at(10), Key: "b", Value: []int{2}},
+				{Start: at(10), End: at(20), Key: "a", Value: []int{4}},
+				{Start: at(10), End: at(20), Key: "b", Value: []int{5}},
+				{Start: at(20), End: at(30), Key: "a", Value: []int{6}},
+			},
+		},
+		"Should publish the sliding windows": {
+			events: []event{{"", 1, 1}, {"", 6, 2}, {"", 12, 3}},
+			et: EventTime[event, string]{
+				Timestamp: event.Timestamp,
+				Windows:   SlidingWindows(10*time.Second, 5*time.Second),
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(-5), End: at(5), Value: []int{1}},
+				{Start: at(0), End: at(10), Value: []int{1, 2}},
+				{Start: at(5), End: at(15), Value: []int{2, 3}},
+				{Start: at(10), End: at(20), Value: []int{3}},
+			},
+		},
+		"Should publish the session windows by key": {
+			events: []event{{"a", 0, 1}, {"a", 3, 2}, {"b", 4, 3}, {"a", 10, 4}, {"a", 20, 5}},
+			et: EventTime[event, string]{
+				Timestamp: event.Timestamp,
+				Key:       event.Key,
+				Windows:   SessionWindows(5 * time.Second),
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(0), End: at(8), Key: "a", Value: []int{1, 2}},
+				{Start: at(4), End: at(9), Key: "b", Value: []int{3}},
+				{Start: at(10), End: at(15), Key: "a", Value: []int{4}},
+				{Start: at(20), End: at(25), Key: "a", Value: []int{5}},
+			},
+		},
+		"Should merge the sessions bridged by an element": {
+			events: []event{{"a", 0, 1}, {"a", 8, 2}, {"a", 4, 3}},
+			et: EventTime[event, string]{
+				Timestamp: event.Timestamp,
+				Windows:   SessionWindows(5 * time.Second),
+				Watermark: BoundedOutOfOrderness(10 * time.Second),
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(0), End: at(13), Value: []int{1, 2, 3}},
+			},
+		},
+		"Should update the windows with late elements during the allowed lateness": {
+			events: []event{{"", 1, 1}, {"", 12, 2}, {"", 8, 3}, {"", 16, 4}, {"", 9, 5}, {"", 31, 6}, {"", 2, 7}},
+			et: EventTime[event, string]{
+				Timestamp:       event.Timestamp,
+				Windows:         TumblingWindows(10 * time.Second),
+				Watermark:       BoundedOutOfOrderness(5 * time.Second),
+				AllowedLateness: 10 * time.Second,
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(0), End: at(10), Value: []int{1, 3}},
+				{Start: at(0), End: at(10), Value: []int{1, 3, 5}},
+				{Start: at(10), End: at(20), Value: []int{2, 4}},
+				{Start: at(30), End: at(40), Value: []int{6}},
+			},
+		},
+		"Should publish again a session extended by a late element": {
+			events: []event{{"", 0, 1}, {"", 6, 2}, {"", 4, 3}, {"", 20, 4}},
+			et: EventTime[event, string]{
+				Timestamp:       event.Timestamp,
+				Windows:         SessionWindows(5 * time.Second),
+				AllowedLateness: 10 * time.Second,
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(0), End: at(5), Value: []int{1}},
+				{Start: at(0), End: at(11), Value: []int{1, 2, 3}},
+				{Start: at(20), End: at(25), Value: []int{4}},
+			},
+		},
+		"Should merge a published session into a larger one": {
+			events: []event{{"", 0, 1}, {"", 6, 2}, {"", 7, 3}, {"", 4, 4}, {"", 30, 5}},
+			et: EventTime[event, string]{
+				Timestamp:       event.Timestamp,
+				Windows:         SessionWindows(5 * time.Second),
+				AllowedLateness: 20 * time.Second,
+			},
+			want: []WindowResult[string, []int]{
+				{Start: at(0), End: at(5), Value: []int{1}},
+				// the larger session comes first
+				{Start: at(0), End: at(12), Value: []int{2, 3, 1, 4}},
+				{Start: at(30), End: at(35), Value: []int{5}},
+			},
+		},
+		"Should return an empty Stream when empty": {
+			events: []event{},
+			et: EventTime[event, string]{
+				Timestamp: event.Timestamp,
+				Windows:   TumblingWindows(time.Second),
+			},
+			want: []WindowResult[string, []int]{},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			got := WindowByEventTime(NewStreamFromSlice(tc.events, 0), tc.et, vals).ToSlice()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEventWindows_DiscardsTheExpiredWindows(t *testing.T) {
+	tt := map[string]struct {
+		windows Windows
+	}{
+		"Tumbling": {windows: TumblingWindows(time.Second)},
+		"Session":  {windows: SessionWindows(time.Second)},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			w := newEventWindows(EventTime[event, string]{
+				Timestamp: event.Timestamp,
+				Key:       event.Key,
+				Windows:   tc.windows,
+				Watermark: BoundedOutOfOrderness(0),
+			})
+
+			fired := 0
+			for i := 0; i < 1000; i++ {
+				fired += len(w.add(event{key: strconv.Itoa(i % 3), at: 2 * i}))
+			}
+
+			// each element passes the watermark beyond the end of the window of the previous
+			// one: only the window of the last element is kept.
+			assert.Equal(t, 999, fired)
+			assert.Equal(t, 1, len(w.index)+len(w.sessions))
+			assert.Equal(t, 1, w.kept.Len())
+			assert.Equal(t, 1, w.pending.Len())
+			assert.Len(t, w.flush(), 1)
+		})
+	}
+}
+
+func TestWindowByEventTime_LongSession(t *testing.T) {
+	const n = 100_000
+
+	events := make([]event, n)
+	for i := range events {
+		events[i] = event{at: i, val: i}
+	}
+
+	// a single session: each element extends it in place.
+	got := WindowByEventTime(
+		FromSlice(events),
+		EventTime[event, string]{Timestamp: event.Timestamp, Windows: SessionWindows(5 * time.Second)},
+		Mapping(event.Val, ToSlice[int]()),
+	).ToSlice()
+
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, at(0), got[0].Start)
+		assert.Equal(t, at(n+4), got[0].End)
+		assert.Len(t, got[0].Value, n)
+	}
+}
+
+func TestWindowByEventTime_GroupingBy(t *testing.T) {
+	events := NewStreamFromSlice([]event{{"a", 1, 1}, {"b", 2, 2}, {"a", 3, 3}, {"a", 12, 4}}, 0)
+
+	got := WindowByEventTime(
+		events,
+		EventTime[event, struct{}]{
+			Timestamp: event.Timestamp,
+			Windows:   TumblingWindows(10 * time.Second),
+		},
+		GroupingBy(event.Key, Mapping(event.Val, ToSlice[int]())),
+	).HeadN(1)
+
+	assert.Equal(t, []WindowResult[struct{}, map[string][]int]{
+		{Start: at(0), End: at(10), Value: map[string][]int{"a": {1, 3}, "b": {2}}},
+	}, got)
+}
+
+func TestWindowByEventTime_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, PanicNilNotPermitted, func() {
+		WindowByEventTime(Stream[event]{}, EventTime[event, string]{Windows: TumblingWindows(time.Second)}, ToSlice[event]())
+	})
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() {
+		WindowByEventTime(Stream[event]{}, EventTime[event, string]{Timestamp: event.Timestamp}, ToSlice[event]())
+	})
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { TumblingWindows(0) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { SlidingWindows(time.Second, 0) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { SessionWindows(-time.Second) })
+}
@@ -0,0 +1,139 @@
+package fuego
+
+import (
+	"context"
+	"sync"
+)
+
+// Concat returns a Stream of the elements of the given Streams, one Stream after the other.
+//
+// Each Stream is run when the previous one is closed. The out-stream is closed when the
+// last Stream is closed. The out-stream inherits the settings of the first Stream.
+// Concat of no Streams is an empty Stream.
+//
+// The errors and panics of all the Streams are handled by the out-stream, in accordance
+// with its error policy.
+func Concat[T any](streams ...Stream[T]) Stream[T] {
+	if len(streams) == 0 {
+		return NewStreamFromSlice([]T{}, 0)
+	}
+
+	return derive(streams[0], func(ctx context.Context, yield func(T) bool) {
+		errs := errorsOf(ctx)
+
+		for _, s := range streams {
+			stopped := false
+
+			func() {
+				defer errs.rethrow()
+
+				s.run(ctx, func(val T) bool {
+					stopped = !yield(val)
+					return !stopped
+				})
+			}()
+
+			if stopped || cancelled(ctx.Done()) {
+				return
+			}
+		}
+	})
+}
+
+// Merge returns a Stream of the elements of the given Streams, in the order in which they
+// are published.
+//
+// Each Stream is run in its own goroutine and publishes its elements to a channel buffered
+// to the concurrency level of the first Stream. The out-stream is closed when all the Streams
+// are closed. The out-stream inherits the settings of the first Stream.
+// Merge of no Streams is an empty Stream.
+//
+// The errors and panics of all the Streams are handled by the out-stream, in accordance
+// with its error policy. A panic raised by one of the Streams is recovered and re-raised
+// by the terminal operation, unless the out-stream propagates errors.
+func Merge[T any](streams ...Stream[T]) Stream[T] {
+	if len(streams) == 0 {
+		return NewStreamFromSlice([]T{}, 0)
+	}
+
+	return derive(streams[0], func(ctx context.Context, yield func(T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		c := make(chan T, streams[0].concurrency)
+		errs := errorsOf(ctx)
+
+		var wg sync.WaitGroup
+
+		for _, s := range streams {
+			wg.Add(1)
+
+			go func(s Stream[T]) {
+				defer wg.Done()
+				defer errs.recoverPanic()
+
+				done := ctx.Done()
+
+				s.run(ctx, func(val T) bool {
+					return send(done, c, val)
+				})
+			}(s)
+		}
+
+		go func() {
+			wg.Wait()
+			close(c)
+		}()
+
+		NewStream(c).run(ctx, yield)
+	})
+}
+
+// Interleave returns a Stream of the elements of the given Streams, taken in turn.
+//
+// The first element of each Stream is published, then the second of each Stream, and so on.
+// A Stream that is closed leaves the rotation and the out-stream is closed when all the Streams
+// are closed. Each Stream is run in its own goroutine and buffers its elements up to its
+// concurrency level. The out-stream inherits the settings of the first Stream.
+// Interleave of no Streams is an empty Stream.
+//
+// The errors and panics of all the Streams are handled by the out-stream, in accordance
+// with its error policy.
+func Interleave[T any](streams ...Stream[T]) Stream[T] {
+	if len(streams) == 0 {
+		return NewStreamFromSlice([]T{}, 0)
+	}
+
+	return derive(streams[0], func(ctx context.Context, yield func(T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		nexts := make([]func() (T, bool), 0, len(streams))
+
+		for _, s := range streams {
+			next, stop := pull(ctx, s.materialise(ctx))
+			defer stop()
+
+			nexts = append(nexts, next)
+		}
+
+		for len(nexts) > 0 {
+			live := nexts[:0]
+
+			for _, next := range nexts {
+				val, ok := next()
+				if !ok {
+					continue
+				}
+
+				if !yield(val) {
+					return
+				}
+
+				live = append(live, next)
+			}
+
+			nexts = live
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package fuego
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcat(t *testing.T) {
+	tt := map[string]struct {
+		streams []Stream[int]
+		want    []int
+	}{
+		"Should return an empty Stream when no Streams": {
+			streams: nil,
+			want:    []int{},
+		},
+		"Should concatenate the Streams in order": {
+			streams: []Stream[int]{
+				NewStreamFromSlice([]int{1, 2}, 0),
+				NewStreamFromSlice([]int{}, 0),
+				NewStreamFromSlice([]int{3}, 0).Filter(True[int]()),
+				Stream[int]{},
+				NewStreamFromSlice([]int{4, 5}, 0),
+			},
+			want: []int{1, 2, 3, 4, 5},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Concat(tc.streams...).ToSlice())
+		})
+	}
+}
+
+func TestConcat_IsLazy(t *testing.T) {
+	second := false
+
+	s := Concat(
+		NewStreamFromSlice([]int{1, 2}, 0),
+		NewStreamFromSlice([]int{3, 4}, 0).Peek(func(int) { second = true }),
+	)
+
+	assert.Equal(t, []int{1, 2}, s.HeadN(2))
+	assert.False(t, second, "the second Stream should not be run")
+	assert.Equal(t, []int{1, 2, 3, 4}, s.ToSlice())
+}
+
+func TestMerge(t *testing.T) {
+	assert.Equal(t, []int{}, Merge[int]().ToSlice())
+
+	got := Merge(
+		NewStreamFromSlice([]int{1, 2, 3}, 0),
+		NewStreamFromSlice([]int{4, 5}, 0).Filter(True[int]()),
+		Stream[int]{},
+		NewConcurrentStream(func() chan int {
+			c := make(chan int, 1)
+			c <- 6
+			close(c)
+			return c
+		}(), 2),
+	).ToSlice()
+
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6}, got)
+}
+
+func TestMerge_FirstComeFirstServed(t *testing.T) {
+	slow := make(chan int)
+	defer close(slow)
+
+	fast := NewStreamFromSlice([]int{1, 2, 3}, 0)
+
+	assert.Equal(t, []int{1, 2, 3}, Merge(NewStream(slow), fast).HeadN(3))
+}
+
+func TestMerge_StopsTheStreams(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	naturals := func() Stream[int] {
+		return FromSeq(func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		})
+	}
+
+	assert.Len(t, Merge(naturals(), naturals()).Concurrent(4).HeadN(10), 10)
+
+	waitForGoroutines(t, numGoroutines, "the Streams were not stopped")
+}
+
+func TestMerge_RecoversPanics(t *testing.T) {
+	boom := NewStreamFromSlice([]int{1, 2, 3}, 0).Peek(func(i int) {
+		if i == 2 {
+			panic("boom")
+		}
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(r.(*PanicError).Value)
+			}
+		}()
+		Merge(NewStreamFromSlice([]int{4}, 0), boom).ToSlice()
+	})
+}
+
+func TestInterleave(t *testing.T) {
+	tt := map[string]struct {
+		streams []Stream[int]
+		want    []int
+	}{
+		"Should return an empty Stream when no Streams": {
+			streams: nil,
+			want:    []int{},
+		},
+		"Should take the elements in turn": {
+			streams: []Stream[int]{
+				NewStreamFromSlice([]int{1, 4, 7}, 0),
+				NewStreamFromSlice([]int{2, 5, 8}, 0).Filter(True[int]()),
+				NewConcurrentStream(func() chan int {
+					c := make(chan int, 3)
+					c <- 3
+					c <- 6
+					c <- 9
+					close(c)
+					return c
+				}(), 3),
+			},
+			want: []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		"Should carry on with the Streams that are not closed": {
+			streams: []Stream[int]{
+				NewStreamFromSlice([]int{1}, 0),
+				Stream[int]{},
+				NewStreamFromSlice([]int{2, 4, 5}, 0),
+				NewStreamFromSlice([]int{3}, 0),
+			},
+			want: []int{1, 2, 3, 4, 5},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Interleave(tc.streams...).ToSlice())
+		})
+	}
+}
+
+func TestInterleave_StopsTheStreams(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	naturals := FromSeq(func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}).Concurrent(4)
+
+	assert.Equal(t, []int{0, 0, 1, 1}, Interleave(naturals, naturals).HeadN(4))
+
+	waitForGoroutines(t, numGoroutines, "the Streams were not stopped")
+}
+
+func TestFanIn_ReportsTheErrorsOfAllTheStreams(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tt := map[string]struct {
+		fanIn func(...Stream[int]) Stream[int]
+	}{
+		"Concat":     {fanIn: Concat[int]},
+		"Merge":      {fanIn: Merge[int]},
+		"Interleave": {fanIn: Interleave[int]},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+
+		t.Run(name+" error", func(t *testing.T) {
+			second := FromSlice([]int{3, 4}).FilterE(func(i int) (bool, error) {
+				if i == 4 {
+					return false, errBoom
+				}
+				return true, nil
+			})
+
+			_, err := tc.fanIn(FromSlice([]int{1, 2}), second).ToSliceE()
+			assert.ErrorIs(t, err, errBoom)
+		})
+
+		t.Run(name+" panic", func(t *testing.T) {
+			second := FromSlice([]int{3, 4}).Peek(func(i int) {
+				if i == 4 {
+					panic("boom")
+				}
+			})
+
+			_, err := tc.fanIn(FromSlice([]int{1, 2}), second).ToSliceE()
+
+			var pe *PanicError
+			if assert.ErrorAs(t, err, &pe) {
+				assert.Equal(t, "boom", pe.Value)
+			}
+		})
+	}
+}
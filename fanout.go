@@ -0,0 +1,328 @@
+package fuego
+
+import (
+	"context"
+	"sync"
+)
+
+// BackPressure determines how the out-streams of a split operation, such as Tee, Partition
+// or Route, handle a consumer that falls behind. See Stream.WithBackPressure.
+type BackPressure int
+
+const (
+	// SpillWhenFull grows the buffer of an out-stream beyond its size once it is full,
+	// so that a slow or stalled consumer neither holds back the others nor loses elements.
+	// The out-streams can be consumed one after the other, at the expense of memory: the
+	// elements that a consumer falls behind by are held until it reads them.
+	// This is the default policy.
+	SpillWhenFull BackPressure = iota
+
+	// SlowestGoverns holds the pipeline back while the buffer of an out-stream is full,
+	// so that the slowest consumer governs the pace of all the out-streams. No element
+	// is lost and the memory is bounded, but an out-stream that is not consumed stalls
+	// the others once its buffer is full: the out-streams must be consumed concurrently.
+	SlowestGoverns
+
+	// DropWhenFull drops the elements published to an out-stream whose buffer is full,
+	// so that a slow or stalled consumer does not hold back the others. A consumer loses
+	// the elements published while it falls behind by more than the buffer size.
+	DropWhenFull
+)
+
+// Tee returns n Streams that each publish all the elements of this Stream.
+//
+// The pipeline of this Stream runs once, when the first of the Streams is run, and each element
+// is published to all the Streams. The buffer size of the Streams and the BackPressure policy
+// that determines what happens when a buffer is full are set with WithBackPressure.
+//
+// Each of the Streams can only be run once. When a Stream stops, the others carry on.
+// Panics if n < 0.
+func (s Stream[T]) Tee(n int) []Stream[T] {
+	if n < 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return fanOut(s, n, func(T) int { return broadcast })
+}
+
+// Broadcast is a synonym for Tee.
+func (s Stream[T]) Broadcast(n int) []Stream[T] {
+	return s.Tee(n)
+}
+
+// Partition returns a Stream of the elements of this Stream that match the given predicate
+// and a Stream of those that do not.
+//
+// The pipeline of this Stream runs once, when the first of the Streams is run. The buffer
+// size of the Streams and the BackPressure policy that determines what happens when a buffer
+// is full are set with WithBackPressure.
+//
+// Each of the Streams can only be run once. When a Stream stops, the elements routed to it
+// are dropped and the other carries on.
+func (s Stream[T]) Partition(p Predicate[T]) (matched, unmatched Stream[T]) {
+	streams := fanOut(s, 2, func(val T) int {
+		if p(val) {
+			return 0
+		}
+
+		return 1
+	})
+
+	return streams[0], streams[1]
+}
+
+// Route returns n Streams and publishes each element of this Stream to the Stream
+// whose index is the result of keyFn modulo n. A negative result counts from the end,
+// such that -1 routes to the last Stream.
+//
+// The pipeline of this Stream runs once, when the first of the Streams is run. The buffer
+// size of the Streams and the BackPressure policy that determines what happens when a buffer
+// is full are set with WithBackPressure.
+//
+// Each of the Streams can only be run once. When a Stream stops, the elements routed to it
+// are dropped and the others carry on.
+// Panics if n < 1.
+func (s Stream[T]) Route(n int, keyFn Function[T, int]) []Stream[T] {
+	if n < 1 {
+		panic(PanicInvalidArgument)
+	}
+
+	return fanOut(s, n, func(val T) int {
+		i := keyFn(val) % n
+		if i < 0 {
+			i += n
+		}
+
+		return i
+	})
+}
+
+// broadcast is the route of the elements published to all the Streams of a fanOut.
+const broadcast = -1
+
+// fanOut returns n Streams that share a single run of the pipeline of s.
+// Each element of s is published to the Stream whose index is returned by route,
+// or to all the Streams when route returns broadcast.
+//
+// The pipeline of s runs in a new goroutine when the first of the Streams is run.
+// Each Stream has a branch that buffers the elements published to it, in accordance with
+// the BackPressure policy of s. An element routed to a Stream whose terminal operation has
+// completed is dropped. The pipeline stops when the terminal operations of all the Streams
+// have completed.
+//
+// Each of the Streams can only be run once.
+// The errors of the shared run, including a panic raised by the pipeline, are reported to
+// the error sink of the run of each of the Streams when it completes.
+func fanOut[T any](s Stream[T], n int, route func(T) int) []Stream[T] {
+	errs := s.errs.sink()
+
+	branches := make([]*branch[T], n)
+	for i := range branches {
+		branches[i] = newBranch[T](s.pressure, s.buffer)
+	}
+
+	var (
+		start     sync.Once
+		mu        sync.Mutex
+		remaining = n
+		cancel    context.CancelFunc
+	)
+
+	run := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(withSink(s.Context(), errs))
+		errs.cancel = cancel
+
+		go func() {
+			defer func() {
+				for _, b := range branches {
+					b.close()
+				}
+			}()
+			defer errs.recoverPanic()
+
+			done := ctx.Done()
+
+			s.run(ctx, func(val T) bool {
+				var i int
+				if err := try(func() { i = route(val) }); err != nil {
+					return errs.report(err)
+				}
+
+				if i != broadcast {
+					return branches[i].publish(done, val)
+				}
+
+				for _, b := range branches {
+					if !b.publish(done, val) {
+						return false
+					}
+				}
+
+				return true
+			})
+		}()
+	}
+
+	streams := make([]Stream[T], n)
+
+	for i := range streams {
+		var stop sync.Once
+
+		streams[i] = derive(s, func(ctx context.Context, yield func(T) bool) {
+			start.Do(run)
+
+			defer errorsOf(ctx).merge(errs)
+			defer stop.Do(func() {
+				branches[i].stop()
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if remaining--; remaining == 0 {
+					cancel()
+				}
+			})
+
+			branches[i].consume(ctx.Done(), yield)
+		})
+	}
+
+	return streams
+}
+
+// branch buffers the elements published to one of the Streams of a fanOut.
+//
+// Once its buffer is full, a branch grows it (SpillWhenFull), holds back the publisher
+// (SlowestGoverns) or drops the elements (DropWhenFull).
+type branch[T any] struct {
+	pressure BackPressure
+	bufsize  int
+
+	mu      sync.Mutex
+	queue   []T
+	stopped bool // the terminal operation of the Stream has completed
+	closed  bool // no more elements will be published
+
+	ready chan struct{} // signals the consumer that the branch changed
+	space chan struct{} // signals the publisher that the branch changed
+}
+
+func newBranch[T any](pressure BackPressure, bufsize int) *branch[T] {
+	return &branch[T]{
+		pressure: pressure,
+		bufsize:  bufsize,
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+	}
+}
+
+// publish adds val to the branch, waiting for space in accordance with the policy of the branch,
+// unless done is closed first. It returns false when done is closed.
+func (b *branch[T]) publish(done <-chan struct{}, val T) bool {
+	for {
+		b.mu.Lock()
+
+		if b.stopped {
+			b.mu.Unlock()
+			return true
+		}
+
+		if b.accepts() {
+			b.queue = append(b.queue, val)
+			b.mu.Unlock()
+			signal(b.ready)
+
+			return true
+		}
+
+		if b.pressure == DropWhenFull {
+			b.mu.Unlock()
+			return true
+		}
+
+		b.mu.Unlock()
+
+		select {
+		case <-b.space:
+		case <-done:
+			return false
+		}
+	}
+}
+
+// accepts returns whether an element can be added to the branch without waiting.
+// The lock of b must be held.
+func (b *branch[T]) accepts() bool {
+	if b.pressure == SpillWhenFull {
+		return true
+	}
+
+	// a branch holds at least one element, which is then handed over to the consumer
+	return len(b.queue) < max(b.bufsize, 1)
+}
+
+// consume passes the elements of the branch to yield until the branch is closed and empty,
+// done is closed or yield returns false.
+func (b *branch[T]) consume(done <-chan struct{}, yield func(T) bool) {
+	for {
+		b.mu.Lock()
+
+		if len(b.queue) > 0 {
+			var zero T
+
+			val := b.queue[0]
+			b.queue[0] = zero
+			b.queue = b.queue[1:]
+			b.mu.Unlock()
+			signal(b.space)
+
+			if !yield(val) {
+				return
+			}
+
+			continue
+		}
+
+		closed := b.closed
+		b.mu.Unlock()
+
+		if closed {
+			return
+		}
+
+		select {
+		case <-b.ready:
+		case <-done:
+			return
+		}
+	}
+}
+
+// stop records that the terminal operation of the Stream of the branch has completed:
+// the elements of the branch are released and the next ones are dropped.
+func (b *branch[T]) stop() {
+	b.mu.Lock()
+	b.stopped, b.queue = true, nil
+	b.mu.Unlock()
+
+	signal(b.space)
+}
+
+// close records that no more elements will be published to the branch.
+func (b *branch[T]) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	signal(b.ready)
+}
+
+// signal notifies the goroutine waiting on c, if any, without blocking.
+// c must be buffered so that a notification sent before the goroutine waits is not lost.
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,305 @@
+package fuego
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanOut_StopsWhenAllStreamsStop(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	naturals := FromSeq(func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	})
+
+	streams := fanOut(naturals, 2, func(i int) int { return i % 2 })
+
+	var odds []int
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		odds = streams[1].HeadN(3)
+	}()
+
+	assert.Equal(t, []int{0, 2, 4, 6}, streams[0].HeadN(4))
+	<-done
+	assert.Equal(t, []int{1, 3, 5}, odds)
+
+	waitForGoroutines(t, numGoroutines, "the pipeline was not stopped")
+}
+
+func TestFanOut_RecoversPanics(t *testing.T) {
+	streams := fanOut(NewStreamFromSlice([]int{1, 2, 3}, 0), 1, func(i int) int {
+		if i == 2 {
+			panic("boom")
+		}
+		return 0
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(r.(*PanicError).Value)
+			}
+		}()
+		streams[0].ToSlice()
+	})
+}
+
+func TestStream_Tee(t *testing.T) {
+	streams := NewStreamFromSlice([]int{1, 2, 3, 4}, 0).Tee(3)
+
+	got := make([][]int, len(streams))
+	done := make(chan struct{})
+
+	for i := range streams {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			got[i] = streams[i].ToSlice()
+		}(i)
+	}
+
+	for range streams {
+		<-done
+	}
+
+	assert.Equal(t, [][]int{{1, 2, 3, 4}, {1, 2, 3, 4}, {1, 2, 3, 4}}, got)
+}
+
+func TestStream_Broadcast_OneStreamStops(t *testing.T) {
+	streams := NewStreamFromSlice([]int{1, 2, 3, 4}, 0).Broadcast(2)
+
+	var head []int
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		head = streams[0].HeadN(1)
+	}()
+
+	assert.Equal(t, []int{1, 2, 3, 4}, streams[1].ToSlice())
+	<-done
+	assert.Equal(t, []int{1}, head)
+}
+
+func TestStream_Tee_DropWhenFull(t *testing.T) {
+	c := make(chan int)
+
+	streams := NewStream(c).
+		WithBackPressure(DropWhenFull, 2).
+		Tee(2)
+
+	// the second Stream is not consumed while the first one is: it keeps the
+	// elements that fit in its buffer and drops the others.
+	it := streams[0].Iterator()
+	defer it.Close()
+
+	got := []int{}
+
+	for i := 1; i <= 6; i++ {
+		go func() { c <- i }()
+		val, _ := it.Next()
+		got = append(got, val)
+	}
+
+	close(c)
+
+	_, ok := it.Next()
+	assert.False(t, ok)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, got)
+	assert.Equal(t, []int{1, 2}, streams[1].ToSlice())
+}
+
+func TestStream_Tee_DropWhenFull_SlowStreamDoesNotHoldBackTheOthers(t *testing.T) {
+	in := make([]int, 50)
+	for i := range in {
+		in[i] = i
+	}
+
+	streams := FromSlice(in).
+		WithBackPressure(DropWhenFull, 2).
+		Tee(2)
+
+	release := make(chan struct{})
+
+	var slow []int
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		slow = streams[1].Peek(func(int) { <-release }).ToSlice()
+	}()
+
+	fast := make(chan []int)
+
+	go func() {
+		fast <- streams[0].ToSlice()
+	}()
+
+	// the slow Stream is blocked on its first element until the fast one completes.
+	select {
+	case got := <-fast:
+		assert.Subset(t, in, got)
+		assert.IsIncreasing(t, got)
+	case <-time.After(time.Second):
+		t.Fatal("the slow Stream held back the fast one")
+	}
+
+	close(release)
+	<-done
+
+	// the slow Stream keeps the elements that fit in its buffer, and the element handed
+	// over to it if it started consuming before the pipeline completed.
+	assert.GreaterOrEqual(t, len(slow), 2)
+	assert.LessOrEqual(t, len(slow), 3)
+	assert.IsIncreasing(t, slow)
+}
+
+func TestStream_Partition(t *testing.T) {
+	evens, odds := NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6}, 0).Partition(func(i int) bool { return i%2 == 0 })
+
+	var gotOdds []int
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		gotOdds = odds.ToSlice()
+	}()
+
+	assert.Equal(t, []int{2, 4, 6}, evens.ToSlice())
+	<-done
+	assert.Equal(t, []int{1, 3, 5}, gotOdds)
+}
+
+func TestStream_Partition_ConsumedOneAfterTheOther(t *testing.T) {
+	evens, odds := FromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8}).
+		Partition(func(i int) bool { return i%2 == 0 })
+
+	// under the default SpillWhenFull policy, the elements of the second
+	// Stream are held until it is consumed.
+	assert.Equal(t, []int{2, 4, 6, 8}, evens.ToSlice())
+	assert.Equal(t, []int{1, 3, 5, 7}, odds.ToSlice())
+}
+
+func TestStream_Route(t *testing.T) {
+	streams := NewStreamFromSlice([]int{0, 1, 2, 3, 4, 5, 6, -1}, 0).Route(3, func(i int) int { return i })
+
+	got := make([][]int, len(streams))
+	done := make(chan struct{})
+
+	for i := range streams {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			got[i] = streams[i].ToSlice()
+		}(i)
+	}
+
+	for range streams {
+		<-done
+	}
+
+	assert.Equal(t, [][]int{{0, 3, 6}, {1, 4}, {2, 5, -1}}, got)
+}
+
+func TestStream_Route_StalledStream(t *testing.T) {
+	streams := NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, 0).
+		WithBackPressure(DropWhenFull, 8).
+		Route(2, func(i int) int {
+			if i == 9 {
+				return 1 // dead letter
+			}
+			return 0
+		})
+
+	// the dead letter Stream is not consumed but does not hold back the other Stream.
+	// The buffers are large enough for the elements routed to them.
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, streams[0].ToSlice())
+	assert.Equal(t, []int{9}, streams[1].ToSlice())
+}
+
+func TestStream_Route_ConsumedOneAfterTheOther(t *testing.T) {
+	streams := FromSlice([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, -1}).
+		Route(3, func(i int) int { return i })
+
+	// the Streams are consumed one after the other, in reverse order.
+	got := make([][]int, len(streams))
+	for i := len(streams) - 1; i >= 0; i-- {
+		got[i] = streams[i].ToSlice()
+	}
+
+	assert.Equal(t, [][]int{{0, 3, 6, 9}, {1, 4, 7}, {2, 5, 8, -1}}, got)
+}
+
+func TestStream_Partition_BlockedStreamDoesNotHoldBackTheOther(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tt := map[string]struct {
+		pressure BackPressure
+		lossless bool
+	}{
+		"SpillWhenFull": {pressure: SpillWhenFull, lossless: true},
+		"DropWhenFull":  {pressure: DropWhenFull},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			matched, unmatched := FromSlice(in).
+				WithBackPressure(tc.pressure, 1).
+				Partition(func(i int) bool { return i%2 == 0 })
+
+			release := make(chan struct{})
+
+			var gotMatched []int
+			done := make(chan struct{})
+
+			// the matched Stream is running but blocked on its first element.
+			go func() {
+				defer close(done)
+				gotMatched = matched.Peek(func(int) { <-release }).ToSlice()
+			}()
+
+			odds := make(chan []int)
+
+			go func() {
+				odds <- unmatched.ToSlice()
+			}()
+
+			var gotUnmatched []int
+
+			select {
+			case gotUnmatched = <-odds:
+			case <-time.After(time.Second):
+				t.Fatal("the blocked Stream held back the other one")
+			}
+
+			close(release)
+			<-done
+
+			if tc.lossless {
+				assert.Equal(t, []int{2, 4, 6, 8}, gotMatched)
+				assert.Equal(t, []int{1, 3, 5, 7}, gotUnmatched)
+			} else {
+				assert.Subset(t, []int{2, 4, 6, 8}, gotMatched)
+				assert.Subset(t, []int{1, 3, 5, 7}, gotUnmatched)
+			}
+		})
+	}
+}
+
+func TestStream_FanOut_Panics(t *testing.T) {
+	s := FromSlice([]int{1, 2, 3})
+
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { s.Tee(-1) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { s.Route(0, func(i int) int { return i }) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { s.Route(-1, func(i int) int { return i }) })
+	assert.NotPanics(t, func() { s.Tee(0) })
+}
@@ -3,6 +3,9 @@ package fuego
 // Function that accepts one argument and produces a result.
 type Function[T, R any] func(T) R
 
+// FunctionE is a fallible Function that accepts one argument and produces a result or an error.
+type FunctionE[T, R any] func(T) (R, error)
+
 // BiFunction that accepts two arguments and produces a result.
 type BiFunction[T, U, R any] func(T, U) R
 
@@ -17,17 +20,24 @@ type BinaryOperator[T any] func(T, T) T
 // into a Stream[R].
 type StreamFunction[T, R any] func(T) Stream[R]
 
+// StreamFunctionE is a fallible StreamFunction that accepts one argument and produces a Stream[R] or an error.
+type StreamFunctionE[T, R any] func(T) (Stream[R], error)
+
 // FlattenSlice is a StreamFunction that flattens a []T slice to a Stream[Any] of its elements.
+//
+// bufsize is ignored since the Streams are lazy (see FromSlice). It is kept for compatibility.
 func FlattenSlice[T any](bufsize int) StreamFunction[[]T, Any] {
 	return func(el []T) Stream[Any] {
-		return NewStreamFromSlice(el, bufsize).StreamAny()
+		return FromSlice(el).StreamAny()
 	}
 }
 
 // FlattenTypedSlice is a StreamFunction that flattens a []T slice to a Stream[T] of its elements.
+//
+// bufsize is ignored since the Streams are lazy (see FromSlice). It is kept for compatibility.
 func FlattenTypedSlice[T any](bufsize int) StreamFunction[[]T, T] {
 	return func(el []T) Stream[T] {
-		return NewStreamFromSlice(el, bufsize)
+		return FromSlice(el)
 	}
 }
 
@@ -1,6 +1,6 @@
 module github.com/seborama/fuego/v12
 
-go 1.20
+go 1.23
 
 require (
 	github.com/google/go-cmp v0.6.0
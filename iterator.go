@@ -0,0 +1,97 @@
+package fuego
+
+import (
+	"context"
+	"iter"
+)
+
+// Iterator is a pull-style iterator over the elements of a Stream.
+//
+// Iterator must be closed when it is no longer used, unless it was exhausted.
+type Iterator[T any] struct {
+	next func() (T, bool)
+	stop func()
+}
+
+// Iterator returns a pull-style Iterator over the elements of this Stream.
+//
+// This is a terminal operation.
+func (s Stream[T]) Iterator() Iterator[T] {
+	next, stop := iter.Pull(s.All())
+
+	return Iterator[T]{
+		next: next,
+		stop: stop,
+	}
+}
+
+// Next returns the next element of the Stream and true, or the zero value of T and false
+// when the Stream is exhausted.
+func (i Iterator[T]) Next() (T, bool) {
+	return i.next()
+}
+
+// Close releases the Iterator and stops the producers of the Stream.
+func (i Iterator[T]) Close() {
+	i.stop()
+}
+
+// All returns an iter.Seq over the elements of this Stream, for use in a for-range loop.
+//
+// Breaking out of the loop stops the producers of the Stream (see WithContext).
+//
+// This is a terminal operation.
+func (s Stream[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s := s.start()
+
+		defer s.terminate()
+
+		s.each(yield)
+	}
+}
+
+// FromSeq creates a new Stream from an iter.Seq.
+//
+// The Stream is lazy: the elements of the sequence are published to the pipeline each time
+// a terminal operation runs it, after which the stream is closed.
+func FromSeq[T any](seq iter.Seq[T]) Stream[T] {
+	return Stream[T]{
+		seq: func(ctx context.Context, yield func(T) bool) {
+			done := ctx.Done()
+
+			for e := range seq {
+				if cancelled(done) || !yield(e) {
+					return
+				}
+			}
+		},
+		last: &lastRun{},
+	}
+}
+
+// FromSeq2 creates a new Stream of Tuple2 from an iter.Seq2.
+//
+// The pairs of the sequence are published to the stream after which the stream is closed.
+func FromSeq2[K, V any](seq iter.Seq2[K, V]) Stream[Tuple2[K, V]] {
+	return FromSeq(func(yield func(Tuple2[K, V]) bool) {
+		for k, v := range seq {
+			if !yield(NewTuple2(k, v)) {
+				return
+			}
+		}
+	})
+}
+
+// ToSeq2 returns an iter.Seq2 over the pairs of a Stream of Tuple2.
+//
+// This is a terminal operation.
+func ToSeq2[K, V any](s Stream[Tuple2[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for t := range s.All() {
+			if !yield(t.E1, t.E2) {
+				return
+			}
+		}
+	}
+}
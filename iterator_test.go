@@ -0,0 +1,76 @@
+package fuego
+
+import (
+	"maps"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream_Iterator(t *testing.T) {
+	it := NewStreamFromSlice([]int{1, 2, 3}, 0).Map(functionTimesTwo).Iterator()
+
+	got := []any{}
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []any{2, 4, 6}, got)
+
+	_, ok := it.Next()
+	assert.False(t, ok)
+	it.Close()
+}
+
+func TestStream_Iterator_Close(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	it := NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).Concurrent(2).Map(functionTimesTwo).Iterator()
+
+	v, ok := it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	it.Close()
+
+	_, ok = it.Next()
+	assert.False(t, ok)
+
+	waitForGoroutines(t, numGoroutines, "Close must stop the goroutines of the pipeline")
+}
+
+func TestStream_All(t *testing.T) {
+	got := []int{}
+	for v := range NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).Filter(intGreaterThanPredicate(1)).All() {
+		if v > 4 {
+			break
+		}
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{2, 3, 4}, got)
+}
+
+func TestFromSeq(t *testing.T) {
+	got := FromSeq(slices.Values([]int{1, 2, 3})).ToSlice()
+	assert.Equal(t, []int{1, 2, 3}, got)
+
+	// an infinite sequence is stopped along with the stream
+	naturals := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2}, FromSeq(naturals).Limit(3).ToSlice())
+}
+
+func TestFromSeq2_ToSeq2(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	got := FromSeq2(maps.All(m)).ToSlice()
+	assert.ElementsMatch(t, []Tuple2[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}, got)
+
+	assert.Equal(t, m, maps.Collect(ToSeq2(FromSeq2(maps.All(m)))))
+}
@@ -10,42 +10,77 @@ type MathableStream[T Mathable] struct {
 // This is a special case of a reduction.
 // This is a terminal operation and hence expects the producer to close the stream in order to complete.
 func (s MathableStream[T]) Sum() T {
-	if s.stream == nil {
-		panic(PanicMissingChannel)
-	}
-
-	sum, ok := <-s.stream
-	if !ok {
-		panic(PanicNoSuchElement)
-	}
-
-	for val := range s.stream {
-		sum = Sum(sum, val)
-	}
-
+	sum, _ := s.mustSumCount()
 	return sum
 }
 
+// SumOpt is the Optional counterpart of Sum: it returns an empty Optional
+// rather than panicking when the channel is nil or the stream is empty.
+func (s MathableStream[T]) SumOpt() Optional[T] {
+	sum, _, ok := s.sumCount()
+	return optionalIf(sum, ok)
+}
+
 // Average returns the arithmetic average of the numbers in the stream.
 // Panics if the channel is nil or the stream is empty.
 // This is a special case of a reduction.
 // This is a terminal operation and hence expects the producer to close the stream in order to complete.
 func (s MathableStream[T]) Average() T {
-	if s.stream == nil {
+	sum, cnt := s.mustSumCount()
+	return sum / cnt
+}
+
+// AverageOpt is the Optional counterpart of Average: it returns an empty Optional
+// rather than panicking when the channel is nil or the stream is empty.
+func (s MathableStream[T]) AverageOpt() Optional[T] {
+	sum, cnt, ok := s.sumCount()
+	if !ok {
+		return OptionalEmpty[T]()
+	}
+
+	return OptionalOf(sum / cnt)
+}
+
+// mustSumCount returns the sum and the number of the items on the stream.
+// Panics if the channel is nil or the stream is empty.
+func (s MathableStream[T]) mustSumCount() (T, T) {
+	if s.missingChannel() {
 		panic(PanicMissingChannel)
 	}
 
-	sum, ok := <-s.stream
+	sum, cnt, ok := s.sumCount()
 	if !ok {
 		panic(PanicNoSuchElement)
 	}
 
-	var cnt T = 1
+	return sum, cnt
+}
+
+// sumCount returns the sum and the number of the items on the stream, and whether
+// there was any: false when the channel is nil or the stream is empty.
+//
+// The number of items is counted in T for the purpose of Average: it wraps around
+// for the small integer types, hence it is not used to tell whether there was any item.
+func (s MathableStream[T]) sumCount() (T, T, bool) {
+	s.Stream = s.Stream.start()
+
+	defer s.terminate()
 
-	for val := range s.stream {
+	var sum, cnt T
+
+	if s.missingChannel() {
+		return sum, cnt, false
+	}
+
+	found := false
+
+	s.each(func(val T) bool {
 		sum += val
 		cnt++
-	}
+		found = true
 
-	return sum / cnt
+		return true
+	})
+
+	return sum, cnt, found
 }
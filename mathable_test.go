@@ -85,3 +85,28 @@ func TestMathableStream_Average(t *testing.T) {
 		})
 	}
 }
+
+func TestMathableStream_SumOpt(t *testing.T) {
+	assert.Equal(t, OptionalEmpty[int](), MC(Stream[Any]{stream: nil}, Int).SumOpt())
+	assert.Equal(t, OptionalEmpty[int](), MC(NewStreamFromSlice([]Any{}, 0), Int).SumOpt())
+	assert.Equal(t, OptionalOf(12), MC(NewStreamFromSlice([]int{3, 7, 2}, 0).Map(ToAny[int]), Int).SumOpt())
+}
+
+func TestMathableStream_Sum_CountWrapsAround(t *testing.T) {
+	ones := make([]Any, 256)
+	for i := range ones {
+		ones[i] = uint8(1)
+	}
+
+	// the number of elements wraps around to 0 in uint8, as does their sum
+	s := MC(FromSlice(ones), Uint8)
+
+	assert.NotPanics(t, func() { assert.Equal(t, uint8(0), s.Sum()) })
+	assert.Equal(t, OptionalOf(uint8(0)), s.SumOpt())
+}
+
+func TestMathableStream_AverageOpt(t *testing.T) {
+	assert.Equal(t, OptionalEmpty[int](), MC(Stream[Any]{stream: nil}, Int).AverageOpt())
+	assert.Equal(t, OptionalEmpty[int](), MC(NewStreamFromSlice([]Any{}, 0), Int).AverageOpt())
+	assert.Equal(t, OptionalOf(4), MC(NewStreamFromSlice([]int{3, 7, 2}, 0).Map(ToAny[int]), Int).AverageOpt())
+}
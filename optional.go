@@ -114,6 +114,15 @@ func OptionalOf[T any](val T) Optional[T] {
 	}
 }
 
+// optionalIf returns an Optional describing val when ok is true, otherwise an empty Optional.
+func optionalIf[T any](val T, ok bool) Optional[T] {
+	if !ok {
+		return OptionalEmpty[T]()
+	}
+
+	return OptionalOf(val)
+}
+
 func isNil(v any) bool {
 	// hat tip to TeaEntityLab/fpGo:
 	// https://github.com/TeaEntityLab/fpGo/blob/5a35fcbc23e384be5f9b33069e3e3ecc9c661bf4/fp.go#L1218
@@ -0,0 +1,71 @@
+package fuego
+
+// Pipeline is a reusable definition of the intermediate operations that
+// transform a Stream[T] into a Stream[R].
+//
+// Since the operations of a Stream are lazy, applying a Pipeline merely records
+// its stages: nothing runs until a terminal operation is invoked on the resulting
+// Stream. This lets a Pipeline be defined once and applied to several sources.
+//
+// Example:
+//
+//	evenSquares := Pipeline[int, Any](func(s Stream[int]) Stream[Any] {
+//		return s.Filter(isEven).Map(square)
+//	})
+//	evenSquares.Apply(NewStreamFromSlice(a, 0)).ToSlice()
+//	evenSquares.Apply(NewStream(c)).ForEach(print)
+type Pipeline[T, R any] func(Stream[T]) Stream[R]
+
+// Apply returns the Stream that results from applying this Pipeline to source.
+func (p Pipeline[T, R]) Apply(source Stream[T]) Stream[R] {
+	return p(source)
+}
+
+// Then returns a Pipeline that applies p followed by next.
+func Then[T, R, U any](p Pipeline[T, R], next Pipeline[R, U]) Pipeline[T, U] {
+	return func(s Stream[T]) Stream[U] {
+		return next(p(s))
+	}
+}
+
+// MapTo returns a Pipeline of a single typed Map stage.
+func MapTo[T, R any](mapper Function[T, R]) Pipeline[T, R] {
+	return func(s Stream[T]) Stream[R] {
+		return Map(s, mapper)
+	}
+}
+
+// FlatMapTo returns a Pipeline of a single typed FlatMap stage.
+func FlatMapTo[T, R any](mapper StreamFunction[T, R]) Pipeline[T, R] {
+	return func(s Stream[T]) Stream[R] {
+		return FlatMap(s, mapper)
+	}
+}
+
+// Pipe applies the Pipeline p to s.
+//
+// Pipe, Pipe2, Pipe3 and Pipe4 let a chain of typed stages read left-to-right:
+//
+//	words := Pipe3(NewStreamFromSlice(lines, 0),
+//		FlatMapTo(splitWords),
+//		MapTo(strings.ToLower),
+//		func(s Stream[string]) Stream[string] { return s.Distinct(hash) },
+//	)
+func Pipe[T, R any](s Stream[T], p Pipeline[T, R]) Stream[R] {
+	return p(s)
+}
+
+// Pipe2 applies the Pipelines p1 and p2 to s, in this order. See Pipe.
+func Pipe2[T, R1, R any](s Stream[T], p1 Pipeline[T, R1], p2 Pipeline[R1, R]) Stream[R] {
+	return p2(p1(s))
+}
+
+// Pipe3 applies the Pipelines p1, p2 and p3 to s, in this order. See Pipe.
+func Pipe3[T, R1, R2, R any](s Stream[T], p1 Pipeline[T, R1], p2 Pipeline[R1, R2], p3 Pipeline[R2, R]) Stream[R] {
+	return p3(p2(p1(s)))
+}
+
+// Pipe4 applies the Pipelines p1, p2, p3 and p4 to s, in this order. See Pipe.
+func Pipe4[T, R1, R2, R3, R any](s Stream[T], p1 Pipeline[T, R1], p2 Pipeline[R1, R2], p3 Pipeline[R2, R3], p4 Pipeline[R3, R]) Stream[R] {
+	return p4(p3(p2(p1(s))))
+}
@@ -0,0 +1,58 @@
+package fuego
+
+import (
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline_Apply(t *testing.T) {
+	calls := 0
+
+	doubleOfGreaterThanTwo := Pipeline[int, Any](func(s Stream[int]) Stream[Any] {
+		return s.
+			Filter(intGreaterThanPredicate(2)).
+			Peek(func(int) { calls++ }).
+			Map(functionTimesTwo)
+	})
+
+	fromSlice := doubleOfGreaterThanTwo.Apply(NewStreamFromSlice([]int{1, 2, 3, 4}, 0))
+
+	c := make(chan int, 3)
+	c <- 5
+	c <- 1
+	c <- 6
+	close(c)
+
+	fromChannel := doubleOfGreaterThanTwo.Apply(NewStream(c))
+
+	assert.Zero(t, calls)
+	assert.Equal(t, []Any{6, 8}, fromSlice.ToSlice())
+	assert.Equal(t, []Any{10, 12}, fromChannel.ToSlice())
+	assert.Equal(t, 4, calls)
+}
+
+func TestPipe(t *testing.T) {
+	splitWords := func(line string) Stream[string] {
+		return NewStreamFromSlice(strings.Fields(line), 0)
+	}
+
+	distinct := func(s Stream[string]) Stream[string] {
+		return s.Distinct(func(w string) uint32 { return crc32.ChecksumIEEE([]byte(w)) })
+	}
+
+	got := Pipe4(NewStreamFromSlice([]string{"The quick fox", "the lazy dog"}, 0),
+		FlatMapTo(splitWords),
+		MapTo(strings.ToLower),
+		distinct,
+		MapTo(func(w string) int { return len(w) }),
+	).ToSlice()
+	assert.Equal(t, []int{3, 5, 3, 4, 3}, got)
+
+	lengths := Then(MapTo(strings.TrimSpace), MapTo(func(w string) int { return len(w) }))
+	assert.Equal(t, []int{1, 2}, Pipe(NewStreamFromSlice([]string{" a ", "bb"}, 0), lengths).ToSlice())
+	assert.Equal(t, []int{1, 2}, Pipe2(NewStreamFromSlice([]string{" a ", "bb"}, 0), MapTo(strings.TrimSpace), MapTo(func(w string) int { return len(w) })).ToSlice())
+	assert.Equal(t, []int{1}, Pipe3(NewStreamFromSlice([]string{" a ", "bb"}, 0), MapTo(strings.TrimSpace), MapTo(func(w string) int { return len(w) }), func(s Stream[int]) Stream[int] { return s.Take(1) }).ToSlice())
+}
@@ -4,6 +4,9 @@ package fuego
 // Could also be: `type Predicate[T any] Function[T, bool]`.
 type Predicate[T any] func(t T) bool
 
+// PredicateE is a fallible Predicate that returns a boolean or an error.
+type PredicateE[T any] func(t T) (bool, error)
+
 // And is a composed predicate that represents a short-circuiting logical
 // AND of this predicate and another.
 func (p Predicate[T]) And(other Predicate[T]) Predicate[T] {
@@ -3,6 +3,7 @@ package fuego
 //go:generate ./bin/maptoXXX
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,7 +13,8 @@ import (
 // Stream is a sequence of elements supporting sequential and
 // (in specific circumstances) parallel operations.
 //
-// A Stream is a wrapper over a Go channel ('nil' channels are prohibited).
+// A Stream is a wrapper over a Go channel ('nil' channels are prohibited)
+// or over a lazy pipeline of operations.
 //
 // NOTE:
 //
@@ -33,9 +35,47 @@ import (
 // Should the producer not close the channel unintentionally, the Go function will stray.
 //
 // Streams created from a slice are bounded since the slice has finite content.
+//
+// Laziness
+//
+// Intermediate operations such as Filter, Map or Take do not start any goroutine: they record
+// a stage of the pipeline. The pipeline only runs when a terminal operation such as ForEach,
+// ToSlice, Count or Collect is invoked. Goroutines are started at this point for the concurrent
+// operations of the pipeline (see Concurrent) and they are released when the terminal
+// operation completes.
+//
+// Consecutive sequential operations are fused: they run on the same goroutine as a single
+// function and channels are only inserted at concurrency boundaries.
+//
+// A Stream created from a slice or from an iter.Seq can be run by several terminal operations.
+// A Stream created from a Go channel consumes the channel. See also Pipeline.
+//
+// Errors
+//
+// Fallible operations such as MapE, FilterE and FlatMapE report their errors to the pipeline
+// in accordance with its ErrorPolicy (see WithErrorPolicy). The errors can be retrieved with
+// Err() or directly from terminal operations such as ForEachE, ToSliceE or CollectE.
+//
+// Cancellation
+//
+// A Stream may be bound to a context.Context with NewStreamWithContext or Stream.WithContext.
+// All the operations of such a Stream stop as soon as the context is cancelled. A terminal
+// operation that completes early (e.g. AnyMatch or Head) stops the goroutines of the pipeline
+// whether the Stream is bound to a context or not.
 type Stream[T any] struct {
-	stream      chan T
+	stream      chan T                                        // channel of a Stream created from a Go channel
+	seq         func(ctx context.Context, yield func(T) bool) // stages of a lazy Stream
 	concurrency int
+	ctx         context.Context // context of the pipeline, nil unless set with WithContext
+	errs        *errorHandling  // error settings of the pipeline
+	last        *lastRun        // errors of the last run of this Stream
+	unordered   bool            // concurrent operations publish their results in completion order
+	batch       int             // number of elements moved per channel operation by concurrent operations
+	pressure    BackPressure    // policy of the Streams of Tee, Partition and Route towards a slow consumer
+	buffer      int             // size of the buffer of each of the Streams of Tee, Partition and Route
+	clock       Clock           // clock of the time-based operations, nil for the system clock
+	slice       []T             // elements of a slice-backed Stream
+	sliced      bool            // the Stream is slice-backed: seq publishes the elements of slice
 }
 
 // NewStream creates a new Stream.
@@ -50,24 +90,111 @@ func NewConcurrentStream[T any](c chan T, n int) Stream[T] {
 	return Stream[T]{
 		stream:      c,
 		concurrency: n,
+		last:        &lastRun{},
 	}
 }
 
-// NewStreamFromSlice creates a new Stream from a Go slice.
+// NewStreamWithContext creates a new Stream bound to the provided context.
+//
+// This function does not close the provided channel.
+func NewStreamWithContext[T any](ctx context.Context, c chan T) Stream[T] {
+	return NewStream(c).WithContext(ctx)
+}
+
+// FromSlice creates a new Stream from a Go slice.
+//
+// The Stream is lazy: the slice data is published to the pipeline each time a terminal
+// operation runs it, after which the stream is closed.
+//
+// The Stream is slice-backed: Drop, Take and Intersperse return slice-backed Streams,
+// and terminal operations such as ToSlice, Count and Collect read the slice directly.
+// Sequential operations such as Filter, Map and Distinct iterate the slice on the
+// goroutine of the terminal operation. Goroutines and channels are only involved
+// once a concurrent operation is introduced (see Concurrent).
 //
-// The slice data is published to the stream after which the stream is closed.
+// As with FromSeq, no buffer size is involved: the buffers of the channels of a pipeline
+// are sized after its concurrency level.
+func FromSlice[T any](slice []T) Stream[T] {
+	return fromSlice(Stream[T]{}, slice)
+}
+
+// NewStreamFromSlice creates a new Stream from a Go slice. See FromSlice.
+//
+// bufsize is ignored since the Stream is lazy. It is kept for compatibility: new code
+// should use FromSlice.
 func NewStreamFromSlice[T any](slice []T, bufsize int) Stream[T] {
-	c := make(chan T, bufsize)
+	return FromSlice(slice)
+}
 
-	go func() {
-		defer close(c) // slices have finite size: close stream after all data was read.
+// fromSlice creates a slice-backed Stream over slice that inherits the settings of s.
+func fromSlice[T, U any](s Stream[T], slice []U) Stream[U] {
+	out := derive(s, func(ctx context.Context, yield func(U) bool) {
+		done := ctx.Done()
 
 		for _, element := range slice {
-			c <- element
+			if cancelled(done) || !yield(element) {
+				return
+			}
 		}
-	}()
+	})
+	out.slice, out.sliced = slice, true
+
+	return out
+}
+
+// backing returns the elements of this Stream and true when it is slice-backed.
+// No element is returned when the context of the Stream is cancelled.
+func (s Stream[T]) backing() ([]T, bool) {
+	if !s.sliced {
+		return nil, false
+	}
+
+	if cancelled(s.Context().Done()) {
+		return nil, true
+	}
+
+	return s.slice, true
+}
+
+// WithContext returns a Stream bound to ctx.
+//
+// The whole pipeline of the returned Stream and of the Streams derived from it stops
+// when ctx is cancelled.
+func (s Stream[T]) WithContext(ctx context.Context) Stream[T] {
+	if ctx == nil {
+		panic(PanicNilNotPermitted)
+	}
+
+	s.ctx = ctx
 
-	return NewStream(c)
+	return s
+}
+
+// Context returns the context of this Stream.
+//
+// context.Background() is returned when the Stream is not bound to a context.
+func (s Stream[T]) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+
+	return s.ctx
+}
+
+// Clock returns the clock of the time-based operations of this Stream.
+// This is SystemClock unless set with WithClock.
+func (s Stream[T]) Clock() Clock {
+	if s.clock == nil {
+		return SystemClock{}
+	}
+
+	return s.clock
+}
+
+// WithClock returns a Stream whose time-based operations (such as BufferTime) use the given clock.
+func (s Stream[T]) WithClock(clock Clock) Stream[T] {
+	s.clock = clock
+	return s
 }
 
 // Concurrency returns the stream's concurrency level (i.e. parallelism).
@@ -77,12 +204,14 @@ func (s Stream[T]) Concurrency() int {
 
 // Concurrent sets the level of concurrency for this Stream.
 //
-// This is used for concurrent methods such as Stream.Map.
+// This is used for concurrent methods such as Stream.Map, Stream.Filter,
+// Stream.Peek, Stream.Distinct, Stream.AllMatch and Stream.AnyMatch, which
+// run a fixed pool of n workers.
 //
 // Note that to switch off concurrency, you should provide n = 0.
-// With n = 1, concurrency is internal whereby the Stream writer
-// will not block on writing a single element (i.e. buffered
-// channel of 1). This already provides significant processing gains.
+// With n = 1, the function of the operation runs on a single worker goroutine:
+// it overlaps with the upstream stages and with the consumer of the Stream, but
+// the elements are processed one at a time.
 //
 // Performance:
 //
@@ -98,164 +227,471 @@ func (s Stream[T]) Concurrency() int {
 // likely be slower than without, particularly when no CPU core is
 // available.
 func (s Stream[T]) Concurrent(n int) Stream[T] {
-	// This is not accurate but improves performance (by avoiding the
-	// creation of a new channel and iterating through this one).
-	// It should be safe.
-	return NewConcurrentStream(s.stream, n)
+	s.concurrency = n
+	return s
 }
 
-// Any is an alias for type `any`.
-type Any any
+// Unordered returns a Stream whose concurrent operations (such as Map and FlatMap)
+// publish their results as soon as they are available, rather than in the order
+// of the elements of the in-stream.
+//
+// This removes the head-of-line blocking of ordered concurrency whereby a slow
+// element holds back the results of the elements that follow it. It is suited to
+// pipelines where order is irrelevant.
+//
+// Unordered has no effect on a Stream without concurrency (see Concurrent).
+func (s Stream[T]) Unordered() Stream[T] {
+	s.unordered = true
+	return s
+}
 
-// Map returns a Stream consisting of the result of
-// applying the given function to the elements of this stream.
+// Ordered returns a Stream whose concurrent operations preserve the order of the elements.
+// This is the default.
+func (s Stream[T]) Ordered() Stream[T] {
+	s.unordered = false
+	return s
+}
+
+// Batched returns a Stream whose concurrent operations move the elements between
+// their goroutines in batches of up to n elements, rather than one element per
+// channel operation. The batches are transparent to the functions supplied to the
+// operations, which still receive one element at a time.
 //
-// This function streams continuously until the in-stream is closed at
-// which point the out-stream will be closed too.
-func (s Stream[T]) Map(mapper Function[T, Any]) Stream[Any] {
-	return NewConcurrentStream(orderlyConcurrentDo(s, mapper), s.concurrency)
+// Channel operations dominate the cost of concurrent pipelines when the work per
+// element is cheap. Batching amortises this cost, at the expense of latency: an
+// element may be held until its batch is full or until the in-stream is closed.
+//
+// Batched applies to the operations run by the worker pool (such as Map, FlatMap and
+// Filter, see Concurrent) and to CollectParallel. With n < 2, batching is disabled.
+// This is the default.
+func (s Stream[T]) Batched(n int) Stream[T] {
+	s.batch = n
+	return s
 }
 
-// orderlyConcurrentDo executes a Function on the stream.
-// Execution is concurrent and order is preserved.
-// See note on method Map() about the lack of support for parameterised methods in Go.
-func orderlyConcurrentDo[T, U any](s Stream[T], fn Function[T, U]) chan U {
-	outstream := make(chan U, cap(s.stream))
+// WithBackPressure returns a Stream whose split operations (such as Tee, Partition and Route)
+// handle a slow consumer in accordance with the supplied policy.
+//
+// Each of the out-streams of a split operation buffers up to bufsize elements, and at least
+// one element, which is then handed over to its consumer. The default policy is SpillWhenFull,
+// with no buffer: the out-streams can be consumed one after the other.
+func (s Stream[T]) WithBackPressure(policy BackPressure, bufsize int) Stream[T] {
+	s.pressure = policy
+	s.buffer = bufsize
+	return s
+}
 
-	go func() {
-		defer close(outstream)
+// WithErrorPolicy returns a Stream whose fallible operations handle errors
+// in accordance with the supplied policy.
+//
+// The policy applies to the whole pipeline of the returned Stream and of the Streams derived
+// from it, while the Stream it is called on keeps its own. It also switches the pipeline to
+// error propagation: the panics recovered from the pipeline's functions are handled as errors
+// rather than re-raised by the terminal operation (see PanicError).
+//
+// The default policy is FailFast.
+func (s Stream[T]) WithErrorPolicy(policy ErrorPolicy) Stream[T] {
+	s.errs = s.errs.withPolicy(policy)
+	return s
+}
 
-		if s.stream == nil {
-			return
-		}
+// Err returns the error(s) reported by the fallible operations of this Stream's pipeline
+// during the last run of this Stream by a terminal operation.
+//
+// Under the FailFast policy, this is the first error that occurred.
+// Under the CollectErrors policy, this is the join of all the errors that occurred.
+//
+// Err should be called after a terminal operation completed. Each run of the pipeline
+// starts afresh: the errors of a run are not reported by the next one. The runs of the
+// Streams this Stream was derived from, or that were derived from it (e.g. with Filter),
+// are not reported: only the copies of this Stream with other settings (e.g. with
+// Concurrent) share its runs.
+func (s Stream[T]) Err() error {
+	return s.last.err()
+}
+
+// withErrorPropagation returns this Stream with error settings that propagate errors.
+// The Stream it is called on is left unchanged.
+func (s Stream[T]) withErrorPropagation() Stream[T] {
+	s.errs = s.errs.withPropagation()
+	return s
+}
 
-		pipelineCh := make(chan chan U, s.concurrency)
+// start prepares a run of the pipeline of this Stream by a terminal operation: the returned
+// Stream's context is bound to a new error sink, to which the stages of the pipeline report.
+// The context is cancelled when the sink stops the run (see FailFast) or the run terminates.
+func (s Stream[T]) start() Stream[T] {
+	ctx, cancel := context.WithCancel(s.Context())
 
-		pipelineWriter := func(pipelineWCh chan chan U) {
-			defer close(pipelineWCh)
+	errs := s.errs.sink()
+	errs.cancel = cancel
+	s.last.record(errs)
+
+	s.ctx = withSink(ctx, errs)
+
+	return s
+}
 
-			for val := range s.stream {
-				resultCh := make(chan U, 1)
-				pipelineWCh <- resultCh
+// derive creates a lazy Stream that inherits the settings of s and whose
+// elements are published by seq when a terminal operation runs the pipeline.
+func derive[T, U any](s Stream[T], seq func(ctx context.Context, yield func(U) bool)) Stream[U] {
+	return Stream[U]{
+		seq:         seq,
+		concurrency: s.concurrency,
+		ctx:         s.ctx,
+		errs:        s.errs,
+		last:        &lastRun{},
+		unordered:   s.unordered,
+		batch:       s.batch,
+		pressure:    s.pressure,
+		buffer:      s.buffer,
+		clock:       s.clock,
+	}
+}
+
+// missingChannel returns whether this Stream has neither a channel nor a pipeline.
+func (s Stream[T]) missingChannel() bool {
+	return s.stream == nil && s.seq == nil
+}
+
+// each runs the pipeline of this Stream and calls yield for each of its elements,
+// until the Stream is closed, its context is cancelled or yield returns false.
+func (s Stream[T]) each(yield func(T) bool) {
+	s.run(s.Context(), yield)
+}
 
-				go func(resultCh chan<- U, val T) {
-					defer close(resultCh)
-					resultCh <- fn(val)
-				}(resultCh, val)
+// run is each where ctx is the context of the run.
+func (s Stream[T]) run(ctx context.Context, yield func(T) bool) {
+	if s.seq != nil {
+		s.seq(ctx, yield)
+		return
+	}
+
+	if s.stream == nil {
+		return
+	}
+
+	done := ctx.Done()
+
+	if done == nil {
+		for val := range s.stream {
+			if !yield(val) {
+				return
 			}
 		}
 
-		go func() {
-			pipelineWriter(pipelineCh)
-		}()
+		return
+	}
 
-		pipelineReader := func(pipelineRCh chan chan U) {
-			for resultCh := range pipelineRCh {
-				outstream <- <-resultCh
+	for {
+		select {
+		case val, ok := <-s.stream:
+			if !ok || !yield(val) {
+				return
 			}
+		case <-done:
+			return
 		}
-		pipelineReader(pipelineCh)
+	}
+}
+
+// materialise returns a Stream over a channel to which a new goroutine publishes the
+// elements of this Stream, until ctx is cancelled.
+// A Stream that already has a channel is returned as is.
+//
+// This lets several goroutines consume a lazy Stream. A panic raised by the pipeline
+// is recovered and reported to the error sink of ctx.
+func (s Stream[T]) materialise(ctx context.Context) Stream[T] {
+	if s.seq == nil {
+		return s
+	}
+
+	c := make(chan T, s.concurrency)
+	errs := errorsOf(ctx)
+
+	go func() {
+		defer close(c)
+		defer errs.recoverPanic()
+
+		done := ctx.Done()
+
+		s.run(ctx, func(val T) bool {
+			return send(done, c, val)
+		})
 	}()
 
-	return outstream
+	out := s
+	out.stream, out.seq = c, nil
+
+	return out
 }
 
-// FlatMap takes a StreamFunction to flatten the entries
-// in this stream and produce a new stream.
+// terminate re-raises the panic recovered from the run of the pipeline, if any, and releases
+// the context of the run. It is deferred by terminal operations, after start.
+func (s Stream[T]) terminate() {
+	errs := errorsOf(s.Context())
+	defer errs.stop()
+
+	errs.rethrow()
+}
+
+// send publishes val to c unless done is closed first.
+func send[T any](done <-chan struct{}, c chan<- T, val T) bool {
+	if done == nil {
+		c <- val
+		return true
+	}
+
+	select {
+	case c <- val:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// cancelled returns whether done is closed.
+func cancelled(done <-chan struct{}) bool {
+	if done == nil {
+		return false
+	}
+
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Any is an alias for type `any`.
+type Any any
+
+// Map returns a Stream consisting of the result of
+// applying the given function to the elements of this stream.
+//
+// Map runs concurrently in accordance with the Stream's concurrency level.
+// The order of the elements is preserved, unless the Stream is Unordered.
 //
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
-func (s Stream[T]) FlatMap(mapper StreamFunction[T, Any]) Stream[Any] {
-	return NewConcurrentStream(orderlyConcurrentDoStream(s, mapper), s.concurrency)
+func (s Stream[T]) Map(mapper Function[T, Any]) Stream[Any] {
+	return orderlyConcurrentDo(s, func(val T) (Any, error) {
+		return mapper(val), nil
+	})
 }
 
-// orderlyConcurrentDoStream executes a StreamFunction on the stream.
-// Execution is concurrent and order is preserved.
-func orderlyConcurrentDoStream[T, U any](s Stream[T], streamfn StreamFunction[T, U]) chan U {
-	outstream := make(chan U, cap(s.stream))
+// MapE is the fallible variant of Map.
+//
+// The errors returned by mapper are handled in accordance with the ErrorPolicy of the Stream.
+// The elements that caused an error are not published to the out-stream.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) MapE(mapper FunctionE[T, Any]) Stream[Any] {
+	return orderlyConcurrentDo(s.withErrorPropagation(), mapper)
+}
 
-	go func() {
-		defer close(outstream)
+// orderlyConcurrentDo executes a FunctionE on the stream.
+// Execution is concurrent as per the Stream's concurrency level (see do) and order is preserved.
+// See note on method Map() about the lack of support for parameterised methods in Go.
+func orderlyConcurrentDo[T, U any](s Stream[T], fn FunctionE[T, U]) Stream[U] {
+	return derive(s, func(ctx context.Context, yield func(U) bool) {
+		errs := errorsOf(ctx)
 
-		if s.stream == nil {
-			return
-		}
+		do(ctx, s, fn, func(res result[U]) bool {
+			if res.err != nil {
+				return errs.report(res.err)
+			}
 
-		pipelineCh := make(chan chan Stream[U], s.concurrency)
+			return yield(res.val)
+		})
+	})
+}
 
-		pipelineWriter := func(pipelineWCh chan chan Stream[U]) {
-			defer close(pipelineWCh)
+// MapKeyed returns a Stream consisting of the result of
+// applying the given function to the elements of this stream.
+//
+// MapKeyed runs concurrently in accordance with the Stream's concurrency level,
+// while preserving the order of the elements that share the same key. Each element
+// is assigned to one of n shards by hashFn (as with Distinct), where n is the
+// Stream's concurrency level. The elements of a shard are processed sequentially
+// and the shards are processed in parallel.
+//
+// This is useful e.g. to process the events of each account in order, while the
+// events of different accounts are processed concurrently.
+//
+// The order of the elements of different shards is not preserved.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) MapKeyed(hashFn func(T) uint32, mapper Function[T, Any]) Stream[Any] {
+	if hashFn == nil {
+		panic(PanicNilNotPermitted)
+	}
 
-			for val := range s.stream {
-				resultCh := make(chan Stream[U], 1)
-				pipelineWCh <- resultCh
+	fn := func(val T) (Any, error) {
+		return mapper(val), nil
+	}
+
+	return derive(s, func(ctx context.Context, yield func(Any) bool) {
+		errs := errorsOf(ctx)
 
-				go func(resultCh chan<- Stream[U], val T) {
-					defer close(resultCh)
-					resultCh <- streamfn(val)
-				}(resultCh, val)
+		shardDo(ctx, s, hashFn, fn, func(res result[Any]) bool {
+			if res.err != nil {
+				return errs.report(res.err)
 			}
-		}
 
-		go func() {
-			pipelineWriter(pipelineCh)
-		}()
+			return yield(res.val)
+		})
+	})
+}
 
-		pipelineReader := func(pipelineRCh chan chan Stream[U]) {
-			for resultCh := range pipelineRCh {
-				val := <-resultCh
-				val.ForEach(func(e U) {
-					outstream <- e
-				})
+// FlatMap takes a StreamFunction to flatten the entries
+// in this stream and produce a new stream.
+//
+// FlatMap runs concurrently in accordance with the Stream's concurrency level: the pipelines
+// of the Streams returned by mapper also run concurrently, ahead of their turn.
+// The order of the elements is preserved, unless the Stream is Unordered.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) FlatMap(mapper StreamFunction[T, Any]) Stream[Any] {
+	return orderlyConcurrentDoStream(s, func(val T) (Stream[Any], error) {
+		return mapper(val), nil
+	})
+}
+
+// FlatMapE is the fallible variant of FlatMap.
+//
+// The errors returned by mapper are handled in accordance with the ErrorPolicy of the Stream.
+// The elements that caused an error are not published to the out-stream.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) FlatMapE(mapper StreamFunctionE[T, Any]) Stream[Any] {
+	return orderlyConcurrentDoStream(s.withErrorPropagation(), mapper)
+}
+
+// orderlyConcurrentDoStream executes a StreamFunctionE on the stream.
+// Execution is concurrent as per the Stream's concurrency level (see do) and order is preserved.
+//
+// When s is concurrent, each of the Streams returned by streamfn is materialised by the worker
+// that created it: its pipeline runs in its own goroutine, ahead of its turn, so that the work
+// of the Streams overlaps. Their elements are then published in order.
+func orderlyConcurrentDoStream[T, U any](s Stream[T], streamfn StreamFunctionE[T, U]) Stream[U] {
+	return derive(s, func(ctx context.Context, yield func(U) bool) {
+		// the pipelines of the Streams materialised ahead of their turn stop with this stage
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		errs := errorsOf(ctx)
+		fn := FunctionE[T, Stream[U]](streamfn)
+
+		if s.concurrency > 0 {
+			fn = func(val T) (Stream[U], error) {
+				inner, err := streamfn(val)
+				if err != nil {
+					return inner, err
+				}
+
+				return inner.materialise(ctx), nil
 			}
 		}
-		pipelineReader(pipelineCh)
-	}()
 
-	return outstream
+		do(ctx, s, fn, func(res result[Stream[U]]) bool {
+			if res.err != nil {
+				return errs.report(res.err)
+			}
+
+			sent := true
+
+			res.val.run(ctx, func(e U) bool {
+				sent = yield(e)
+				return sent
+			})
+
+			return sent
+		})
+	})
 }
 
 // Filter returns a stream consisting of the elements of this stream that
 // match the given predicate.
 //
+// The predicate is evaluated concurrently in accordance with the Stream's concurrency level.
+// The order of the elements is preserved, unless the Stream is Unordered.
+//
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) Filter(predicate Predicate[T]) Stream[T] {
-	outstream := make(chan T, cap(s.stream))
-
-	go func() {
-		defer close(outstream)
-
-		if s.stream == nil {
-			return
-		}
+	return s.filter(PredicateE[T](test(predicate)))
+}
 
-		for val := range s.stream {
-			if predicate(val) {
-				outstream <- val
-			}
-		}
-	}()
+// FilterE is the fallible variant of Filter.
+//
+// The errors returned by predicate are handled in accordance with the ErrorPolicy of the Stream.
+// The elements that caused an error are not published to the out-stream.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) FilterE(predicate PredicateE[T]) Stream[T] {
+	return s.withErrorPropagation().filter(predicate)
+}
 
-	return NewConcurrentStream(outstream, s.concurrency)
+// filter returns a stream consisting of the elements of this stream that match the given predicate.
+func (s Stream[T]) filter(predicate PredicateE[T]) Stream[T] {
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		eachDo(ctx, s, FunctionE[T, bool](predicate), func(val T, match bool) bool {
+			return !match || yield(val)
+		})
+	})
 }
 
 // LeftReduce accumulates the elements of this Stream by applying the given function.
 //
 // This is a continuous terminal operation. It will only complete if the producer closes the stream.
 func (s Stream[T]) LeftReduce(f2 BiFunction[T, T, T]) T {
-	if s.stream == nil {
-		var t T
-		return t // TODO: return Optional
-	}
+	res, _ := s.leftReduce(f2)
+	return res
+}
+
+// LeftReduceOpt is the Optional counterpart of LeftReduce: it returns an empty Optional
+// rather than the zero value when the channel is nil or the stream is empty.
+func (s Stream[T]) LeftReduceOpt(f2 BiFunction[T, T, T]) Optional[T] {
+	return optionalIf(s.leftReduce(f2))
+}
 
-	res := <-s.stream
+// leftReduce accumulates the elements of this Stream by applying f2 from left to right.
+// It returns false when the channel is nil or the stream is empty.
+func (s Stream[T]) leftReduce(f2 BiFunction[T, T, T]) (T, bool) {
+	s = s.start()
 
-	for val := range s.stream {
-		res = f2(res, val)
+	defer s.terminate()
+
+	var res T
+
+	if s.missingChannel() {
+		return res, false
 	}
 
-	return res
+	first := true
+	errs := errorsOf(s.Context())
+
+	s.each(func(val T) bool {
+		if first {
+			res, first = val, false
+			return true
+		}
+
+		if err := try(func() { res = f2(res, val) }); err != nil {
+			return errs.report(err)
+		}
+
+		return true
+	})
+
+	return res, !first
 }
 
 // Reduce is an alias for LeftReduce.
@@ -265,36 +701,46 @@ func (s Stream[T]) Reduce(f2 BiFunction[T, T, T]) T {
 	return s.LeftReduce(f2)
 }
 
+// ReduceOpt is an alias for LeftReduceOpt.
+//
+// See LeftReduceOpt for more info.
+func (s Stream[T]) ReduceOpt(f2 BiFunction[T, T, T]) Optional[T] {
+	return s.LeftReduceOpt(f2)
+}
+
 // Intersperse inserts an element between all elements of this Stream.
 //
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) Intersperse(e T) Stream[T] {
-	outstream := make(chan T, cap(s.stream))
-
-	go func() {
-		defer close(outstream)
-
-		if s.stream == nil {
-			return
+	if s.sliced {
+		if len(s.slice) == 0 {
+			return s
 		}
 
-		// this is to get around the inability to test generic types for nil in Go 1.18
-		select {
-		case val, ok := <-s.stream:
-			if !ok {
-				return
+		slice := make([]T, 0, 2*len(s.slice)-1)
+		for i, val := range s.slice {
+			if i > 0 {
+				slice = append(slice, e)
 			}
-			outstream <- val
+			slice = append(slice, val)
 		}
 
-		for val := range s.stream {
-			outstream <- e
-			outstream <- val
-		}
-	}()
+		return fromSlice(s, slice)
+	}
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		first := true
+
+		s.run(ctx, func(val T) bool {
+			if first {
+				first = false
+				return yield(val)
+			}
 
-	return NewConcurrentStream(outstream, s.concurrency)
+			return yield(e) && yield(val)
+		})
+	})
 }
 
 // GroupBy groups the elements of this Stream by classifying them.
@@ -302,19 +748,27 @@ func (s Stream[T]) Intersperse(e T) Stream[T] {
 // This is a continuous terminal operation and hence expects the producer to close the stream
 // in order to complete.
 func (s Stream[T]) GroupBy(classifier Function[T, Any]) map[Any][]T {
-	resultMap := make(map[Any][]T)
+	s = s.start()
 
-	if s.stream != nil {
-		for val := range s.stream {
-			k := classifier(val)
+	defer s.terminate()
 
-			if resultMap[k] == nil {
-				resultMap[k] = []T{}
-			}
+	resultMap := make(map[Any][]T)
+	errs := errorsOf(s.Context())
 
-			resultMap[k] = append(resultMap[k], val)
+	s.each(func(val T) bool {
+		var k Any
+		if err := try(func() { k = classifier(val) }); err != nil {
+			return errs.report(err)
 		}
-	}
+
+		if resultMap[k] == nil {
+			resultMap[k] = []T{}
+		}
+
+		resultMap[k] = append(resultMap[k], val)
+
+		return true
+	})
 
 	return resultMap
 }
@@ -325,14 +779,20 @@ func (s Stream[T]) GroupBy(classifier Function[T, Any]) map[Any][]T {
 // the producer to close the stream in order to complete (or
 // it will block).
 func (s Stream[T]) Count() int {
-	if s.stream == nil {
-		return 0
+	s = s.start()
+
+	defer s.terminate()
+
+	if slice, ok := s.backing(); ok {
+		return len(slice)
 	}
 
 	count := 0
-	for range s.stream {
+
+	s.each(func(T) bool {
 		count++
-	}
+		return true
+	})
 
 	return count
 }
@@ -340,41 +800,58 @@ func (s Stream[T]) Count() int {
 // AllMatch returns whether all of the elements in the stream
 // satisfy the predicate.
 //
+// The predicate is evaluated concurrently in accordance with the Stream's concurrency level.
+//
 // This is a continuous terminal operation and hence expects
 // the producer to close the stream in order to complete (or
 // it will block).
 func (s Stream[T]) AllMatch(p Predicate[T]) bool {
-	if s.stream == nil {
+	s = s.start()
+
+	defer s.terminate()
+
+	if s.missingChannel() {
 		return false
 	}
 
-	for val := range s.stream {
-		if !p(val) {
-			return false
-		}
-	}
+	match := true
 
-	return true
+	eachDo(s.Context(), s, test(p), func(_ T, ok bool) bool {
+		match = ok
+		return match
+	})
+
+	return match
 }
 
 // AnyMatch returns whether any of the elements in the stream
 // satisfies the predicate.
 //
+// The predicate is evaluated concurrently in accordance with the Stream's concurrency level.
+//
 // This is a continuous terminal operation and hence expects
 // the producer to close the stream in order to complete (or
 // it will block).
 func (s Stream[T]) AnyMatch(p Predicate[T]) bool {
-	if s.stream == nil {
-		return false
-	}
+	s = s.start()
 
-	for val := range s.stream {
-		if p(val) {
-			return true
-		}
-	}
+	defer s.terminate()
+
+	match := false
+
+	eachDo(s.Context(), s, test(p), func(_ T, ok bool) bool {
+		match = ok
+		return !match
+	})
 
-	return false
+	return match
+}
+
+// test returns a FunctionE that evaluates p.
+func test[T any](p Predicate[T]) FunctionE[T, bool] {
+	return func(val T) (bool, error) {
+		return p(val), nil
+	}
 }
 
 // NoneMatch returns whether none of the elements in the stream
@@ -392,13 +869,22 @@ func (s Stream[T]) NoneMatch(p Predicate[T]) bool {
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) Drop(n uint64) Stream[T] {
-	return s.DropWhile(func() func(e T) bool {
-		count := uint64(0)
-		return func(e T) bool {
-			count++
-			return count <= n
-		}
-	}())
+	if s.sliced {
+		return fromSlice(s, s.slice[min(n, uint64(len(s.slice))):])
+	}
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		dropped := uint64(0)
+
+		s.run(ctx, func(val T) bool {
+			if dropped < n {
+				dropped++
+				return true
+			}
+
+			return yield(val)
+		})
+	})
 }
 
 // DropWhile drops the first elements of this stream while the predicate
@@ -407,32 +893,30 @@ func (s Stream[T]) Drop(n uint64) Stream[T] {
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) DropWhile(p Predicate[T]) Stream[T] {
-	outstream := make(chan T, cap(s.stream))
-
-	go func() {
-		defer close(outstream)
-
-		if s.stream == nil {
-			return
-		}
-
-		// drop elements as required
-		for val := range s.stream {
-			if p(val) {
-				continue
+	drop := test(p)
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		errs := errorsOf(ctx)
+		dropping := true
+
+		s.run(ctx, func(val T) bool {
+			// drop elements as required, then flush the remainder to outstream
+			if dropping {
+				res := call(drop, val)
+				if res.err != nil {
+					return errs.report(res.err)
+				}
+
+				if res.val {
+					return true
+				}
 			}
-			outstream <- val
 
-			break
-		}
+			dropping = false
 
-		// flush the remainder to outstream
-		for val := range s.stream {
-			outstream <- val
-		}
-	}()
-
-	return NewConcurrentStream(outstream, s.concurrency)
+			return yield(val)
+		})
+	})
 }
 
 // DropUntil drops the first elements of this stream until the predicate
@@ -452,14 +936,26 @@ func (s Stream[T]) Last() T {
 	return s.LastN(1)[0]
 }
 
+// FindLast returns an Optional describing the last element of this stream,
+// or an empty Optional if the channel is nil or the stream is empty.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) FindLast() Optional[T] {
+	last := s.lastN(1)
+	if len(last) == 0 {
+		return OptionalEmpty[T]()
+	}
+
+	return OptionalOf(last[0])
+}
+
 // LastN returns a slice of the last n elements in this stream.
 //
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) LastN(n uint64) []T {
-	const flushTriggerDefault = uint64(100)
-
-	if s.stream == nil {
+	if s.missingChannel() {
 		panic(PanicMissingChannel)
 	}
 
@@ -467,21 +963,52 @@ func (s Stream[T]) LastN(n uint64) []T {
 		panic(PanicNoSuchElement)
 	}
 
-	val, ok := <-s.stream
-	if !ok {
+	last := s.lastN(n)
+	if len(last) == 0 {
 		panic(PanicNoSuchElement)
 	}
 
-	result := []T{val}
+	return last
+}
+
+// FindLastN returns an Optional describing the last n elements of this stream,
+// or an empty Optional if the channel is nil, n is 0 or the stream is empty.
+// The stream may hold fewer than n elements.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) FindLastN(n uint64) Optional[[]T] {
+	last := s.lastN(n)
+	return optionalIf(last, len(last) > 0)
+}
+
+// lastN returns the last n elements of this stream, or fewer if the stream is shorter.
+// It returns nil when the channel is nil or n is 0.
+func (s Stream[T]) lastN(n uint64) []T {
+	const flushTriggerDefault = uint64(100)
+
+	s = s.start()
+
+	defer s.terminate()
+
+	if s.missingChannel() || n < 1 {
+		return nil
+	}
+
+	if slice, ok := s.backing(); ok {
+		return append([]T{}, slice[uint64(len(slice))-min(n, uint64(len(slice))):]...)
+	}
 
-	count := uint64(len(result))
+	result := []T{}
+
+	count := uint64(0)
 	flushTrigger := flushTriggerDefault
 
 	if n > flushTrigger {
 		flushTrigger = n
 	}
 
-	for val = range s.stream {
+	s.each(func(val T) bool {
 		result = append(result, val)
 		if count++; count > flushTrigger {
 			// this is simply to reduce the number of
@@ -489,7 +1016,9 @@ func (s Stream[T]) LastN(n uint64) []T {
 			result = result[uint64(len(result))-n:]
 			count = 0
 		}
-	}
+
+		return true
+	})
 
 	if uint64(len(result)) > n {
 		return result[uint64(len(result))-n:]
@@ -510,6 +1039,23 @@ func (s Stream[T]) Head() T {
 	return head[0]
 }
 
+// FindFirst returns an Optional describing the first element of this stream,
+// or an empty Optional if the channel is nil or the stream is empty.
+//
+// This function only consumes at most one element from the stream.
+func (s Stream[T]) FindFirst() Optional[T] {
+	if s.missingChannel() {
+		return OptionalEmpty[T]()
+	}
+
+	head := s.HeadN(1)
+	if len(head) == 0 {
+		return OptionalEmpty[T]()
+	}
+
+	return OptionalOf(head[0])
+}
+
 // HeadN returns a slice of the first n elements in this stream.
 //
 // This function only consumes at most 'n' elements from the stream.
@@ -529,16 +1075,26 @@ func (s Stream[T]) HeadN(n uint64) []T {
 // or the in-stream  is closed at which point the out-stream
 // will be closed too.
 func (s Stream[T]) Take(n uint64) Stream[T] {
-	counterIsLessThanOrEqualTo := func(maxCount uint64) Predicate[T] {
-		counter := uint64(0)
+	if s.missingChannel() {
+		panic(PanicMissingChannel)
+	}
 
-		return func(t T) bool {
-			counter++
-			return counter <= maxCount
-		}
+	if s.sliced {
+		return fromSlice(s, s.slice[:min(n, uint64(len(s.slice)))])
 	}
 
-	return s.TakeWhile(counterIsLessThanOrEqualTo(n))
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		if n == 0 {
+			return
+		}
+
+		taken := uint64(0)
+
+		s.run(ctx, func(val T) bool {
+			taken++
+			return yield(val) && taken < n
+		})
+	})
 }
 
 // Limit is a synonym for Take.
@@ -552,24 +1108,24 @@ func (s Stream[T]) Limit(n uint64) Stream[T] {
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) TakeWhile(p Predicate[T]) Stream[T] {
-	if s.stream == nil {
+	if s.missingChannel() {
 		panic(PanicMissingChannel)
 	}
 
-	outstream := make(chan T, cap(s.stream))
+	take := test(p)
 
-	go func() {
-		defer close(outstream)
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		errs := errorsOf(ctx)
 
-		for val := range s.stream {
-			if !p(val) {
-				return
+		s.run(ctx, func(val T) bool {
+			res := call(take, val)
+			if res.err != nil {
+				return errs.report(res.err)
 			}
-			outstream <- val
-		}
-	}()
 
-	return NewConcurrentStream(outstream, s.concurrency)
+			return res.val && yield(val)
+		})
+	})
 }
 
 // TakeUntil returns a stream of the first elements
@@ -615,14 +1171,7 @@ func (s Stream[T]) EndsWith(slice []T) bool {
 		return false
 	}
 
-	endElements := func() []T {
-		defer func() {
-			// TODO: this doesn't look great... Need to re-write LastN like HeadN as a collect of TakeRight (to be implemented)
-			_ = recover()
-		}()
-
-		return s.LastN(uint64(len(slice)))
-	}()
+	endElements := s.FindLastN(uint64(len(slice))).OrElse(nil)
 
 	if len(endElements) != len(slice) {
 		return false
@@ -639,37 +1188,110 @@ func (s Stream[T]) EndsWith(slice []T) bool {
 
 // ForEach executes the given consumer function for each entry in this stream.
 //
+// The consumer is called sequentially, in the order of the elements of the stream, which is
+// the ordering guarantee of the concurrent operations such as Map. When the stream is both
+// concurrent and Unordered, this guarantee is lifted: the consumer is called concurrently in
+// accordance with the Stream's concurrency level (see ForEachConcurrent), in which case it
+// must be safe for concurrent use.
+//
 // This is a continuous terminal operation. It will only complete if the producer closes the stream.
 func (s Stream[T]) ForEach(c Consumer[T]) {
-	if s.stream == nil {
+	if s.concurrency > 0 && s.unordered {
+		s.ForEachConcurrent(s.concurrency, c)
+		return
+	}
+
+	s = s.start()
+
+	defer s.terminate()
+
+	if s.missingChannel() {
 		zap.L().Debug("empty stream")
 		return
 	}
 
-	for val := range s.stream {
+	errs := errorsOf(s.Context())
+
+	s.each(func(val T) bool {
 		zap.L().Debug("calling consumer", zap.Any("value", val))
+
+		if err := try(func() { c(val) }); err != nil {
+			return errs.report(err)
+		}
+
+		return true
+	})
+}
+
+// ForEachConcurrent executes the given consumer function for each entry in this stream
+// with a pool of n workers. It returns when all the consumers have completed.
+//
+// The consumer must be safe for concurrent use. The order in which the entries are
+// consumed is not defined. With n < 1, the consumer is called sequentially.
+//
+// This is a continuous terminal operation. It will only complete if the producer closes the stream.
+func (s Stream[T]) ForEachConcurrent(n int, c Consumer[T]) {
+	s = s.start()
+
+	defer s.terminate()
+
+	consume := func(val T) (struct{}, error) {
 		c(val)
+		return struct{}{}, nil
+	}
+
+	eachDo(s.Context(), s.Concurrent(n).Unordered(), consume, func(T, struct{}) bool { return true })
+}
+
+// ForEachE executes the given fallible consumer function for each entry in this stream.
+//
+// The errors returned by the consumer are handled in accordance with the ErrorPolicy of the Stream.
+// ForEachE returns the error(s) reported by the pipeline, if any (see Err).
+//
+// This is a continuous terminal operation. It will only complete if the producer closes the stream.
+func (s Stream[T]) ForEachE(c ConsumerE[T]) error {
+	s = s.withErrorPropagation().start()
+
+	defer s.terminate()
+
+	errs := errorsOf(s.Context())
+
+	consume := func(val T) (struct{}, error) {
+		return struct{}{}, c(val)
 	}
+
+	s.each(func(val T) bool {
+		if res := call(consume, val); res.err != nil {
+			return errs.report(res.err)
+		}
+
+		return true
+	})
+
+	return errs.err()
 }
 
 // Peek is akin to ForEach but returns the Stream.
 //
 // This is useful e.g. for debugging.
 //
+// The consumer is called concurrently in accordance with the Stream's concurrency level,
+// in which case it must be safe for concurrent use. The order of the elements of the
+// out-stream is preserved, unless the Stream is Unordered.
+//
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) Peek(consumer Consumer[T]) Stream[T] {
-	outstream := make(chan T, cap(s.stream))
+	consume := func(val T) (struct{}, error) {
+		consumer(val)
+		return struct{}{}, nil
+	}
 
-	go func() {
-		defer close(outstream)
-		s.ForEach(func(e T) {
-			consumer(e)
-			outstream <- e
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		eachDo(ctx, s, consume, func(val T, _ struct{}) bool {
+			return yield(val)
 		})
-	}()
-
-	return NewConcurrentStream(outstream, s.concurrency)
+	})
 }
 
 // ToSlice extracts the elements of the stream into a []T.
@@ -680,64 +1302,125 @@ func (s Stream[T]) Peek(consumer Consumer[T]) Stream[T] {
 // the producer to close the stream in order to complete (or
 // it will block).
 func (s Stream[T]) ToSlice() []T {
-	result := []T{}
+	s = s.start()
 
-	if s.stream != nil {
-		for val := range s.stream {
-			result = append(result, val)
-		}
+	defer s.terminate()
+
+	if slice, ok := s.backing(); ok {
+		return append(make([]T, 0, len(slice)), slice...)
 	}
 
+	result := []T{}
+
+	s.each(func(val T) bool {
+		result = append(result, val)
+		return true
+	})
+
 	return result
 }
 
+// ToSliceE extracts the elements of the stream into a []T and returns the
+// error(s) reported by the pipeline, if any (see Err).
+//
+// This is a continuous terminal operation and hence expects
+// the producer to close the stream in order to complete (or
+// it will block).
+func (s Stream[T]) ToSliceE() ([]T, error) {
+	s = s.withErrorPropagation().start()
+
+	defer s.terminate()
+
+	result := []T{}
+
+	s.each(func(val T) bool {
+		result = append(result, val)
+		return true
+	})
+
+	return result, errorsOf(s.Context()).err()
+}
+
 // Distinct returns a stream of the distinct elements of this stream.
 // Distinctiveness is determined via the provided hashFn.
 //
 // This operation is costly both in time and in memory. It is
 // strongly recommended to use buffered channels for this operation.
 //
+// The hashes are computed concurrently in accordance with the Stream's concurrency level.
+// The order of the elements is preserved, unless the Stream is Unordered.
+//
 // This function streams continuously until the in-stream is closed at
 // which point the out-stream will be closed too.
 func (s Stream[T]) Distinct(hashFn func(T) uint32) Stream[T] {
-	if s.stream == nil {
+	if s.missingChannel() {
 		panic(PanicMissingChannel)
 	}
 
-	outstream := make(chan T, cap(s.stream))
-
-	go func() {
-		defer close(outstream)
+	hash := func(val T) (string, error) {
+		// hash is prefixed with the type in case T is an interface implemented by 2 or more types
+		// that are present on the stream.
+		return fmt.Sprintf("%T%d", val, hashFn(val)), nil
+	}
 
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
 		unique := map[string]struct{}{}
 
-		for val := range s.stream {
-			// hash is prefixed with the type in case T is an interface implemented by 2 or more types
-			// that are present on the stream.
-			uniqueHash := fmt.Sprintf("%T%d", val, hashFn(val))
-			if _, ok := unique[uniqueHash]; !ok {
-				unique[uniqueHash] = struct{}{}
-				outstream <- val
+		eachDo(ctx, s, hash, func(val T, uniqueHash string) bool {
+			if _, ok := unique[uniqueHash]; ok {
+				return true
 			}
-		}
-	}()
 
-	return NewConcurrentStream(outstream, s.concurrency)
+			unique[uniqueHash] = struct{}{}
+
+			return yield(val)
+		})
+	})
 }
 
-// StreamAny returns this stream as a Stream[Any].
-func (s Stream[T]) StreamAny() Stream[Any] {
-	rCh := make(chan Any, cap(s.stream))
+// DistinctUntilChanged returns a stream of the elements of this stream that differ from
+// the element that precedes them. Equality is determined via the provided eq function.
+//
+// Unlike Distinct, which remembers every element of the stream, DistinctUntilChanged
+// only remembers the last element and hence runs in constant memory.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+func (s Stream[T]) DistinctUntilChanged(eq BiFunction[T, T, bool]) Stream[T] {
+	if s.missingChannel() {
+		panic(PanicMissingChannel)
+	}
 
-	r := NewConcurrentStream(rCh, s.concurrency)
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		var prev T
 
-	go func() {
-		defer close(rCh)
+		first := true
+		errs := errorsOf(ctx)
+
+		s.run(ctx, func(val T) bool {
+			if !first {
+				var same bool
+				if err := try(func() { same = eq(prev, val) }); err != nil {
+					return errs.report(err)
+				}
+
+				if same {
+					return true
+				}
+			}
 
-		s.ForEach(func(el T) {
-			r.stream <- el
+			prev, first = val, false
+
+			return yield(val)
 		})
-	}()
+	})
+}
 
-	return r
+// StreamAny returns this stream as a Stream[Any].
+func (s Stream[T]) StreamAny() Stream[Any] {
+	return derive(s, func(ctx context.Context, yield func(Any) bool) {
+		s.run(ctx, func(el T) bool {
+			return yield(el)
+		})
+	})
 }
@@ -1,10 +1,16 @@
 package fuego
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"hash/crc32"
+	"math"
+	"math/rand"
 	"reflect"
+	"runtime"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -60,6 +66,72 @@ func TestSC(t *testing.T) {
 	}
 }
 
+func TestOfType(t *testing.T) {
+	mixed := []Any{1, "two", 3, 4.0, nil, 5}
+
+	assert.Equal(t, []int{1, 3, 5}, OfType[int](NewStreamFromSlice(mixed, 0)).ToSlice())
+	assert.Equal(t, []string{"two"}, OfType[string](NewStreamFromSlice(mixed, 0)).ToSlice())
+}
+
+func TestCastE(t *testing.T) {
+	mixed := []Any{1, "two", 3, 4.0, 5}
+
+	got, err := CastE[int](NewStreamFromSlice(mixed, 0)).ToSliceE()
+	assert.Equal(t, []int{1}, got)
+
+	var castErr *CastError
+	if assert.ErrorAs(t, err, &castErr) {
+		assert.Equal(t, "two", castErr.Element)
+		assert.Equal(t, reflect.TypeOf(""), castErr.Type)
+		assert.Equal(t, reflect.TypeOf(0), castErr.Target)
+		assert.Equal(t, "cannot cast element 'two' of type string to int", castErr.Error())
+	}
+
+	got, err = CastE[int](NewStreamFromSlice(mixed, 0).WithErrorPolicy(CollectErrors)).ToSliceE()
+	assert.Equal(t, []int{1, 3, 5}, got)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+}
+
+func TestCastOrReject(t *testing.T) {
+	mixed := []Any{1, "two", 3, 4.0, 5}
+
+	ints, rejects := CastOrReject[int](NewStreamFromSlice(mixed, 0))
+
+	var got []Any
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		got = rejects.ToSlice()
+	}()
+
+	assert.Equal(t, []int{1, 3, 5}, ints.ToSlice())
+	<-done
+	assert.Equal(t, []Any{"two", 4.0}, got)
+
+	// the rejects are dropped once their consumer has completed
+	ints, rejects = CastOrReject[int](NewStreamFromSlice(mixed, 0))
+
+	var gotInts []int
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		gotInts = ints.ToSlice()
+	}()
+
+	assert.Equal(t, []Any{"two"}, rejects.HeadN(1))
+	<-done
+	assert.Equal(t, []int{1, 3, 5}, gotInts)
+}
+
+func TestCastOrReject_ConsumedOneAfterTheOther(t *testing.T) {
+	ints, rejects := CastOrReject[int](FromSlice([]Any{1, "two", 3, 4.0, 5}))
+
+	assert.Equal(t, []int{1, 3, 5}, ints.ToSlice())
+	assert.Equal(t, []Any{"two", 4.0}, rejects.ToSlice())
+}
+
 func TestStream_Map(t *testing.T) {
 	tt := map[string]struct {
 		stream Stream[int]
@@ -97,10 +169,8 @@ func TestStream_Map(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			var got []int
 			var resultStream Stream[int] = C(tc.stream.Map(tc.mapper), Int)
-			if gotStream := resultStream.stream; gotStream != nil {
-				for val := range gotStream {
-					got = append(got, val)
-				}
+			for val := range resultStream.All() {
+				got = append(got, val)
 			}
 
 			if !cmp.Equal(tc.want, got) {
@@ -245,6 +315,32 @@ func TestStream_FlatMap_Concurrent(t *testing.T) {
 	assert.WithinDuration(t, end, start, 3*time.Second) // 3 seconds should be plenty enough...
 }
 
+func TestStream_FlatMap_Concurrent_OverlapsInnerStreams(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	slowInner := func(el []int) Stream[Any] {
+		return NewStreamFromSlice(el, 0).Map(func(i int) Any {
+			n := running.Add(1)
+			defer running.Add(-1)
+
+			for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			return i
+		})
+	}
+
+	got := NewStreamFromSlice([][]int{{1, 2}, {3}, {4, 5}, {6}, {7, 8}, {9}}, 0).
+		Concurrent(3).
+		FlatMap(slowInner).
+		ToSlice()
+
+	assert.Equal(t, []Any{1, 2, 3, 4, 5, 6, 7, 8, 9}, got)
+	assert.Greater(t, maxRunning.Load(), int32(1), "the pipelines of the inner Streams should run concurrently")
+}
+
 func TestStream_Filter(t *testing.T) {
 	tt := map[string]struct {
 		stream    chan int
@@ -281,10 +377,8 @@ func TestStream_Filter(t *testing.T) {
 			}
 
 			var got []int
-			if gotStream := s.Filter(tc.predicate).stream; gotStream != nil {
-				for val := range gotStream {
-					got = append(got, val)
-				}
+			for val := range s.Filter(tc.predicate).All() {
+				got = append(got, val)
 			}
 
 			assert.Equal(t, tc.want, got)
@@ -342,6 +436,15 @@ func TestStream_LeftReduce(t *testing.T) {
 	}
 }
 
+func TestStream_ReduceOpt(t *testing.T) {
+	assert.Equal(t, OptionalEmpty[string](), Stream[string]{stream: nil}.ReduceOpt(Concatenate[string]))
+	assert.Equal(t, OptionalEmpty[string](), NewStreamFromSlice([]string{}, 0).ReduceOpt(Concatenate[string]))
+	assert.Equal(t, OptionalOf("three"), NewStreamFromSlice([]string{"three"}, 0).ReduceOpt(Concatenate[string]))
+	assert.Equal(t, OptionalOf("four-three"), NewStreamFromSlice([]string{"four-", "three"}, 0).
+		Filter(True[string]()).
+		LeftReduceOpt(Concatenate[string]))
+}
+
 func TestStream_Intersperse(t *testing.T) {
 	tt := map[string]struct {
 		stream    chan string
@@ -414,7 +517,7 @@ func TestStream_Intersperse(t *testing.T) {
 			}
 			out := s.Intersperse(tc.inBetween)
 			got := []string{}
-			for e := range out.stream {
+			for e := range out.All() {
 				got = append(got, e)
 			}
 			if !assert.ElementsMatch(t, got, tc.want) {
@@ -752,7 +855,7 @@ func TestStream_Drop(t *testing.T) {
 				return
 			}
 			got := []any{}
-			for val := range gotStream.stream {
+			for val := range gotStream.All() {
 				got = append(got, val)
 			}
 			assert.EqualValues(t, tc.want, got)
@@ -816,7 +919,7 @@ func TestStream_DropWhile(t *testing.T) {
 			s := Stream[any]{stream: tc.stream}
 			gotStream := s.DropWhile(tc.p)
 			got := []any{}
-			for val := range gotStream.stream {
+			for val := range gotStream.All() {
 				got = append(got, val)
 			}
 			assert.EqualValues(t, tc.want, got)
@@ -884,7 +987,7 @@ func TestStream_DropUntil(t *testing.T) {
 			s := Stream[any]{stream: tc.stream}
 			gotStream := s.DropUntil(tc.p)
 			got := []any{}
-			for val := range gotStream.stream {
+			for val := range gotStream.All() {
 				got = append(got, val)
 			}
 			assert.EqualValues(t, tc.want, got)
@@ -1010,6 +1113,51 @@ func TestStream_LastN(t *testing.T) {
 	}
 }
 
+func TestStream_FindLast(t *testing.T) {
+	emptyStream := func() chan int {
+		c := make(chan int)
+		close(c)
+		return c
+	}
+
+	assert.Equal(t, OptionalEmpty[int](), Stream[int]{stream: nil}.FindLast())
+	assert.Equal(t, OptionalEmpty[int](), NewStream(emptyStream()).FindLast())
+	assert.Equal(t, OptionalEmpty[int](), NewStreamFromSlice([]int{}, 0).FindLast())
+	assert.Equal(t, OptionalOf(3), NewStreamFromSlice([]int{1, 2, 3}, 0).FindLast())
+	assert.Equal(t, OptionalOf(3), NewStreamFromSlice([]int{1, 2, 3}, 0).Filter(True[int]()).FindLast())
+}
+
+func TestStream_FindLastN(t *testing.T) {
+	assert.Equal(t, OptionalEmpty[[]int](), Stream[int]{stream: nil}.FindLastN(1))
+	assert.Equal(t, OptionalEmpty[[]int](), NewStreamFromSlice([]int{1, 2, 3}, 0).FindLastN(0))
+	assert.Equal(t, OptionalEmpty[[]int](), NewStreamFromSlice([]int{}, 0).Filter(True[int]()).FindLastN(2))
+	assert.Equal(t, OptionalOf([]int{2, 3}), NewStreamFromSlice([]int{1, 2, 3}, 0).FindLastN(2))
+	assert.Equal(t, OptionalOf([]int{1, 2, 3}), NewStreamFromSlice([]int{1, 2, 3}, 0).Filter(True[int]()).FindLastN(5))
+}
+
+func TestStream_FindFirst(t *testing.T) {
+	emptyStream := func() chan int {
+		c := make(chan int)
+		close(c)
+		return c
+	}
+
+	assert.Equal(t, OptionalEmpty[int](), Stream[int]{stream: nil}.FindFirst())
+	assert.Equal(t, OptionalEmpty[int](), NewStream(emptyStream()).FindFirst())
+	assert.Equal(t, OptionalOf(1), NewStreamFromSlice([]int{1, 2, 3}, 0).FindFirst())
+	assert.Equal(t, OptionalOf(2), NewStreamFromSlice([]int{1, 2, 3}, 0).Filter(func(i int) bool { return i%2 == 0 }).FindFirst())
+}
+
+func TestStream_Panics_AreSentinelErrors(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		assert.True(t, ok)
+		assert.ErrorIs(t, err, PanicNoSuchElement)
+	}()
+
+	NewStreamFromSlice([]int{}, 0).Head()
+}
+
 func TestStream_HeadX_PanicsWhenNilChannel(t *testing.T) {
 	assert.PanicsWithValue(t, PanicMissingChannel, func() { Stream[any]{stream: nil}.HeadN(1) })
 	assert.PanicsWithValue(t, PanicMissingChannel, func() { Stream[any]{stream: nil}.Head() })
@@ -1215,7 +1363,7 @@ func TestStream_Take_Limit(t *testing.T) {
 				return
 			}
 			got := []any{}
-			for val := range gotStream.stream {
+			for val := range gotStream.All() {
 				got = append(got, val)
 			}
 			assert.EqualValues(t, tc.want, got)
@@ -1274,7 +1422,7 @@ func TestStream_TakeWhile(t *testing.T) {
 			s := Stream[any]{stream: tc.stream}
 			gotStream := s.TakeWhile(tc.p)
 			got := []any{}
-			for val := range gotStream.stream {
+			for val := range gotStream.All() {
 				got = append(got, val)
 			}
 			assert.EqualValues(t, tc.want, got)
@@ -1336,7 +1484,7 @@ func TestStream_TakeUntil(t *testing.T) {
 			s := Stream[any]{stream: tc.stream}
 			gotStream := s.TakeUntil(tc.p)
 			got := []any{}
-			for val := range gotStream.stream {
+			for val := range gotStream.All() {
 				got = append(got, val)
 			}
 			assert.EqualValues(t, tc.want, got)
@@ -1711,6 +1859,40 @@ func TestStream_Distinct(t *testing.T) {
 	}
 }
 
+func TestStream_DistinctUntilChanged(t *testing.T) {
+	assert.PanicsWithValue(t, PanicMissingChannel, func() { Stream[int]{}.DistinctUntilChanged(func(a, b int) bool { return a == b }) })
+
+	eq := func(a, b int) bool { return a == b }
+
+	tt := map[string]struct {
+		stream Stream[int]
+		want   []int
+	}{
+		"Should return an empty Stream when empty": {
+			stream: NewStreamFromSlice([]int{}, 0),
+			want:   []int{},
+		},
+		"Should suppress the consecutive duplicates": {
+			stream: NewStreamFromSlice([]int{1, 1, 2, 2, 2, 1, 3, 3}, 0),
+			want:   []int{1, 2, 1, 3},
+		},
+		"Should keep the zero value": {
+			stream: NewStreamFromSlice([]int{0, 0, 1}, 0).Filter(True[int]()),
+			want:   []int{0, 1},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.stream.DistinctUntilChanged(eq).ToSlice())
+		})
+	}
+
+	closeEnough := func(a, b float64) bool { return math.Abs(a-b) < 0.5 }
+	assert.Equal(t, []float64{1, 2.1}, NewStreamFromSlice([]float64{1, 1.2, 1.4, 2.1, 2.3}, 0).DistinctUntilChanged(closeEnough).ToSlice())
+}
+
 func TestStream_Peek(t *testing.T) {
 	computeSumTotal := func(callCount, total *int) Consumer[int] {
 		return func(value int) {
@@ -1824,3 +2006,1010 @@ var functionSlowTimesTwo = func() Function[int, Any] {
 		return 2 * i
 	}
 }()
+
+func TestStream_WithContext_CancellationStopsPipeline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := make(chan int) // never closed by the producer
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var received atomic.Int32
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		NewStreamWithContext(ctx, c).
+			Filter(intGreaterThanPredicate(-1)).
+			Intersperse(-1).
+			Peek(func(int) { received.Add(1) }).
+			ForEach(func(i int) {
+				if i > 10 {
+					cancel()
+				}
+			})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pipeline did not stop after context cancellation")
+	}
+
+	assert.Greater(t, received.Load(), int32(10))
+}
+
+func TestStream_WithContext_ShortCircuitReleasesUpstream(t *testing.T) {
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+
+	numGoroutines := runtime.NumGoroutine()
+
+	got := NewStreamFromSlice(data, 0).
+		WithContext(context.Background()).
+		Filter(intGreaterThanPredicate(-1)).
+		DropWhile(intGreaterThanPredicate(1000)).
+		Concurrent(4).
+		Map(functionTimesTwo).
+		AnyMatch(func(e Any) bool { return e.(int) == 10 })
+	assert.True(t, got)
+
+	got = NewStreamFromSlice(data, 0).
+		WithContext(context.Background()).
+		Take(5).
+		Filter(intGreaterThanPredicate(2)).
+		AnyMatch(func(e int) bool { return e == 2 })
+	assert.False(t, got)
+
+	assert.Equal(t, []int{0, 1}, NewStreamFromSlice(data, 10).WithContext(context.Background()).HeadN(2))
+
+	waitForGoroutines(t, numGoroutines, "upstream goroutines were not released")
+}
+
+func TestStream_WithContext_NilContextPanics(t *testing.T) {
+	assert.PanicsWithValue(t, PanicNilNotPermitted, func() {
+		//nolint:staticcheck
+		NewStream(make(chan int)).WithContext(nil)
+	})
+}
+
+func TestStream_Context(t *testing.T) {
+	assert.Equal(t, context.Background(), NewStream(make(chan int)).Context())
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	assert.Equal(t, ctx, NewStreamWithContext(ctx, make(chan int)).Filter(True[int]()).Context())
+}
+
+func TestStream_MapE(t *testing.T) {
+	errOdd := errors.New("odd number")
+
+	evenTimesTwo := func(i int) (Any, error) {
+		if i%2 != 0 {
+			return nil, fmt.Errorf("%d: %w", i, errOdd)
+		}
+		return 2 * i, nil
+	}
+
+	tt := map[string]struct {
+		stream  Stream[int]
+		want    []Any
+		wantErr string
+	}{
+		"Should return an empty Stream when nil channel": {
+			stream: Stream[int]{stream: nil},
+			want:   []Any{},
+		},
+		"Should stop on the first error by default": {
+			stream:  NewStreamFromSlice([]int{2, 4, 5, 6, 7}, 0),
+			want:    []Any{4, 8},
+			wantErr: "5: odd number",
+		},
+		"Should skip errors": {
+			stream: NewStreamFromSlice([]int{2, 4, 5, 6, 7}, 0).WithErrorPolicy(SkipErrors),
+			want:   []Any{4, 8, 12},
+		},
+		"Should collect all errors": {
+			stream:  NewStreamFromSlice([]int{2, 4, 5, 6, 7}, 0).WithErrorPolicy(CollectErrors),
+			want:    []Any{4, 8, 12},
+			wantErr: "5: odd number\n7: odd number",
+		},
+		"Should preserve order when concurrent": {
+			stream:  NewStreamFromSlice([]int{2, 4, 6, 8, 9, 10}, 0).Concurrent(3),
+			want:    []Any{4, 8, 12, 16},
+			wantErr: "9: odd number",
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.stream.MapE(evenTimesTwo).ToSliceE()
+			assert.Equal(t, tc.want, got)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+			assert.ErrorIs(t, err, errOdd)
+		})
+	}
+}
+
+func TestStream_FilterE(t *testing.T) {
+	errNegative := errors.New("negative number")
+
+	isEven := func(i int) (bool, error) {
+		if i < 0 {
+			return false, errNegative
+		}
+		return i%2 == 0, nil
+	}
+
+	got, err := NewStreamFromSlice([]int{1, 2, 3, 4, -5, 6}, 0).FilterE(isEven).ToSliceE()
+	assert.Equal(t, []int{2, 4}, got)
+	assert.ErrorIs(t, err, errNegative)
+
+	got, err = NewStreamFromSlice([]int{1, 2, 3, 4, -5, 6}, 0).WithErrorPolicy(SkipErrors).FilterE(isEven).ToSliceE()
+	assert.Equal(t, []int{2, 4, 6}, got)
+	assert.NoError(t, err)
+}
+
+func TestStream_FailFast_StopsTheWholeRun(t *testing.T) {
+	errTwo := errors.New("two")
+
+	failOnTwo := func(i int) (Any, error) {
+		if i == 2 {
+			return nil, errTwo
+		}
+		return i, nil
+	}
+
+	naturals := FromSeq(func(yield func(Any) bool) {
+		for i := 10; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	})
+
+	tt := map[string]struct {
+		stream func() Stream[Any]
+		want   []Any
+	}{
+		"FlatMap": {
+			stream: func() Stream[Any] {
+				return FromSlice([]int{1, 2, 3}).FlatMap(func(i int) Stream[Any] {
+					return FromSlice([]int{i}).MapE(failOnTwo)
+				})
+			},
+			want: []Any{1},
+		},
+		"Concat": {
+			stream: func() Stream[Any] {
+				return Concat(FromSlice([]int{1, 2, 3}).MapE(failOnTwo), naturals)
+			},
+			want: []Any{1},
+		},
+		"Merge": {
+			stream: func() Stream[Any] {
+				return Merge(FromSlice([]int{1, 2, 3}).MapE(failOnTwo), naturals)
+			},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			type result struct {
+				got []Any
+				err error
+			}
+
+			done := make(chan result)
+
+			go func() {
+				got, err := tc.stream().ToSliceE()
+				done <- result{got: got, err: err}
+			}()
+
+			select {
+			case res := <-done:
+				assert.ErrorIs(t, res.err, errTwo)
+				assert.NotContains(t, res.got, 3)
+				if tc.want != nil {
+					assert.Equal(t, tc.want, res.got)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("the run was not stopped by the error")
+			}
+		})
+	}
+}
+
+func TestStream_FlatMapE(t *testing.T) {
+	errEmpty := errors.New("empty slice")
+
+	flatten := func(el []int) (Stream[Any], error) {
+		if len(el) == 0 {
+			return Stream[Any]{}, errEmpty
+		}
+		return NewStreamFromSlice(el, 0).StreamAny(), nil
+	}
+
+	s := NewStreamFromSlice([][]int{{1, 2}, {}, {3}, {}}, 0).
+		WithErrorPolicy(CollectErrors).
+		Concurrent(2).
+		FlatMapE(flatten)
+
+	got, err := CollectE(s, ToSlice[Any]())
+	assert.Equal(t, []Any{1, 2, 3}, got)
+	assert.EqualError(t, err, "empty slice\nempty slice")
+}
+
+func TestStream_ForEachE(t *testing.T) {
+	errTooLarge := errors.New("too large")
+
+	got := []int{}
+	err := NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).
+		ForEachE(func(i int) error {
+			if i > 3 {
+				return errTooLarge
+			}
+			got = append(got, i)
+			return nil
+		})
+	assert.Equal(t, []int{1, 2, 3}, got)
+	assert.ErrorIs(t, err, errTooLarge)
+
+	got = []int{}
+	err = Stream[int]{}.ForEachE(func(i int) error {
+		got = append(got, i)
+		return nil
+	})
+	assert.Equal(t, []int{}, got)
+	assert.NoError(t, err)
+}
+
+func TestStream_Map_RecoversWorkerPanics(t *testing.T) {
+	panicOnThree := func(i int) Any {
+		if i == 3 {
+			panic("three is not allowed")
+		}
+		return i
+	}
+
+	defer func() {
+		r := recover()
+		if !assert.IsType(t, &PanicError{}, r) {
+			return
+		}
+		pe := r.(*PanicError)
+		assert.Equal(t, "three is not allowed", pe.Value)
+		assert.Contains(t, string(pe.Stack), "TestStream_Map_RecoversWorkerPanics")
+	}()
+
+	NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).
+		Concurrent(4).
+		Map(panicOnThree).
+		ToSlice()
+
+	t.Error("expected a panic to be re-raised by the terminal operation")
+}
+
+func TestStream_RecoversStagePanics(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	recovered := func(fn func()) (r any) {
+		defer func() { r = recover() }()
+		fn()
+		return nil
+	}
+
+	r := recovered(func() {
+		NewStreamFromSlice([]int{1, 2, 3}, 0).
+			Filter(func(i int) bool { panic(errBoom) }).
+			Count()
+	})
+	assert.IsType(t, &PanicError{}, r)
+	assert.ErrorIs(t, r.(error), errBoom)
+
+	r = recovered(func() {
+		NewStreamFromSlice([][]int{{1}, {2}}, 0).
+			FlatMap(func([]int) Stream[Any] { panic("flat") }).
+			ForEach(func(Any) {})
+	})
+	assert.IsType(t, &PanicError{}, r)
+	assert.Equal(t, "flat", r.(*PanicError).Value)
+}
+
+func TestStream_CallbackPanics(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	boomOnThree := func(i int) {
+		if i == 3 {
+			panic(errBoom)
+		}
+	}
+
+	tt := map[string]func(s Stream[int]) Stream[Any]{
+		"DropWhile": func(s Stream[int]) Stream[Any] {
+			return s.DropWhile(func(i int) bool { boomOnThree(i); return i < 4 }).StreamAny()
+		},
+		"TakeWhile": func(s Stream[int]) Stream[Any] {
+			return s.TakeWhile(func(i int) bool { boomOnThree(i); return true }).StreamAny()
+		},
+		"DistinctUntilChanged": func(s Stream[int]) Stream[Any] {
+			return s.DistinctUntilChanged(func(a, b int) bool { boomOnThree(b); return a == b }).StreamAny()
+		},
+		"ChunkBy": func(s Stream[int]) Stream[Any] {
+			return ChunkBy(s, func(i int) int { boomOnThree(i); return i }).StreamAny()
+		},
+		"ZipWith": func(s Stream[int]) Stream[Any] {
+			return ZipWith(s, NewStreamFromSlice([]int{1, 2, 3, 4}, 0), func(a, _ int) Any { boomOnThree(a); return a })
+		},
+	}
+
+	for name, stage := range tt {
+		stage := stage
+
+		t.Run(name, func(t *testing.T) {
+			for _, concurrency := range []int{0, 2} {
+				s := NewStreamFromSlice([]int{1, 2, 3, 4}, 0).Concurrent(concurrency)
+
+				func() {
+					defer func() {
+						r := recover()
+						if assert.IsType(t, &PanicError{}, r, "concurrency=%d", concurrency) {
+							assert.ErrorIs(t, r.(*PanicError), errBoom)
+						}
+					}()
+
+					stage(s).ToSlice()
+				}()
+
+				_, err := stage(s.WithErrorPolicy(CollectErrors)).ToSliceE()
+				assert.ErrorIs(t, err, errBoom, "concurrency=%d", concurrency)
+
+				var pe *PanicError
+				assert.ErrorAs(t, err, &pe)
+			}
+		})
+	}
+
+	assert.PanicsWithValue(t, errBoom, func() {
+		defer func() { panic(recover().(*PanicError).Value) }()
+
+		NewStreamFromSlice([]int{1, 2, 3, 4}, 0).GroupBy(func(i int) Any { boomOnThree(i); return i })
+	})
+
+	err := NewStreamFromSlice([]int{1, 2, 3, 4}, 0).ForEachE(func(i int) error { boomOnThree(i); return nil })
+	assert.ErrorIs(t, err, errBoom)
+}
+
+func TestStream_PanicsAsErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	got, err := NewStreamFromSlice([]int{1, 2, 3, 4}, 0).
+		Concurrent(2).
+		MapE(func(i int) (Any, error) {
+			if i == 2 {
+				panic(errBoom)
+			}
+			return i, nil
+		}).
+		ToSliceE()
+	assert.Equal(t, []Any{1}, got)
+	assert.ErrorIs(t, err, errBoom)
+
+	var pe *PanicError
+	assert.ErrorAs(t, err, &pe)
+
+	got, err = NewStreamFromSlice([]int{1, 2, 3, 4}, 0).
+		WithErrorPolicy(CollectErrors).
+		Map(func(i int) Any {
+			if i%2 == 0 {
+				panic(errBoom)
+			}
+			return i
+		}).
+		ToSliceE()
+	assert.Equal(t, []Any{1, 3}, got)
+	assert.ErrorIs(t, err, errBoom)
+
+	got, err = NewStreamFromSlice([]int{1, 2, 3, 4}, 0).
+		Map(func(i int) Any {
+			if i == 3 {
+				panic(errBoom)
+			}
+			return i
+		}).
+		ToSliceE()
+	assert.Equal(t, []Any{1, 2}, got)
+	assert.ErrorIs(t, err, errBoom)
+}
+
+func TestStream_ErrorSettingsAreNotShared(t *testing.T) {
+	errOdd := errors.New("odd number")
+
+	panicky := func(i int) Any {
+		if i == 2 {
+			panic("two")
+		}
+		return i
+	}
+
+	oddFails := func(i int) (Any, error) {
+		if i%2 != 0 {
+			return nil, errOdd
+		}
+		return i, nil
+	}
+
+	base := NewStreamFromSlice([]int{1, 2, 3}, 0).Map(panicky)
+
+	propagating := base.MapE(func(e Any) (Any, error) { return oddFails(e.(int)) })
+	_, err := propagating.ToSliceE()
+	assert.ErrorIs(t, err, errOdd)
+
+	assert.Panics(t, func() { base.ToSlice() }, "MapE must not switch the Stream it derives from to error propagation")
+
+	failFast := NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).WithErrorPolicy(FailFast)
+	skipping := failFast.WithErrorPolicy(SkipErrors)
+
+	got, err := skipping.MapE(oddFails).ToSliceE()
+	assert.Equal(t, []Any{2, 4}, got)
+	assert.NoError(t, err)
+
+	got, err = failFast.MapE(oddFails).ToSliceE()
+	assert.Equal(t, []Any{}, got)
+	assert.ErrorIs(t, err, errOdd, "WithErrorPolicy must not change the policy of the Stream it is called on")
+}
+
+func TestStream_Unordered(t *testing.T) {
+	slowOnFirst := func(i int) Any {
+		if i == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return 2 * i
+	}
+
+	s := NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8}, 0).Concurrent(4).Unordered()
+	assert.True(t, s.unordered)
+	assert.False(t, s.Ordered().unordered)
+
+	got := s.Map(slowOnFirst).ToSlice()
+	assert.ElementsMatch(t, []Any{2, 4, 6, 8, 10, 12, 14, 16}, got)
+	assert.Equal(t, 2, got[len(got)-1])
+
+	got = NewStreamFromSlice([]int{1, 2, 3, 4}, 0).Concurrent(4).Unordered().Ordered().Map(slowOnFirst).ToSlice()
+	assert.Equal(t, []Any{2, 4, 6, 8}, got)
+}
+
+func TestStream_MapKeyed(t *testing.T) {
+	type event struct {
+		account string
+		seq     int
+	}
+
+	accounts := []string{"alice", "bob", "carol", "dave"}
+
+	events := []event{}
+	for seq := 0; seq < 20; seq++ {
+		for _, account := range accounts {
+			events = append(events, event{account: account, seq: seq})
+		}
+	}
+
+	accountHash := func(e event) uint32 { return crc32.ChecksumIEEE([]byte(e.account)) }
+
+	process := func(e event) Any {
+		time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond) //nolint:gosec
+		return e
+	}
+
+	got := NewStreamFromSlice(events, 0).
+		Concurrent(4).
+		MapKeyed(accountHash, process).
+		ToSlice()
+
+	assert.Len(t, got, len(events))
+
+	lastSeq := map[string]int{}
+	for _, e := range got {
+		e := e.(event)
+		if last, ok := lastSeq[e.account]; ok {
+			assert.Greater(t, e.seq, last, "events of account %s are out of order", e.account)
+		}
+		lastSeq[e.account] = e.seq
+	}
+	assert.Len(t, lastSeq, len(accounts))
+}
+
+func TestStream_MapKeyed_NilHashPanics(t *testing.T) {
+	assert.PanicsWithValue(t, PanicNilNotPermitted, func() {
+		NewStream(make(chan int)).MapKeyed(nil, ToAny[int])
+	})
+}
+
+func TestStream_ConcurrentOperations(t *testing.T) {
+	const numEntries = 100
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	slowly := func(p Predicate[int]) Predicate[int] {
+		return func(i int) bool {
+			time.Sleep(time.Duration(rand.Intn(300)) * time.Microsecond) //nolint:gosec
+			return p(i)
+		}
+	}
+
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	t.Run("Filter", func(t *testing.T) {
+		got := NewStreamFromSlice(data, 0).Concurrent(8).Filter(slowly(isEven)).ToSlice()
+		want := NewStreamFromSlice(data, 0).Filter(isEven).ToSlice()
+		assert.Len(t, got, numEntries/2)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("FilterE", func(t *testing.T) {
+		got, err := NewStreamFromSlice(data, 0).
+			Concurrent(8).
+			FilterE(func(i int) (bool, error) {
+				if i == 51 {
+					return false, errors.New("51")
+				}
+				return slowly(isEven)(i), nil
+			}).
+			ToSliceE()
+		assert.EqualError(t, err, "51")
+		assert.Equal(t, NewStreamFromSlice(data[:51], 0).Filter(isEven).ToSlice(), got)
+	})
+
+	t.Run("Peek", func(t *testing.T) {
+		var sum atomic.Int64
+
+		got := NewStreamFromSlice(data, 0).
+			Concurrent(8).
+			Peek(func(i int) { time.Sleep(100 * time.Microsecond); sum.Add(int64(i)) }).
+			ToSlice()
+		assert.Equal(t, data, got)
+		assert.Equal(t, int64(numEntries*(numEntries-1)/2), sum.Load())
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+		modulo10 := func(i int) uint32 { return uint32(i % 10) }
+
+		got := NewStreamFromSlice(data, 0).Concurrent(8).Distinct(modulo10).ToSlice()
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, got)
+	})
+
+	t.Run("AllMatch and AnyMatch", func(t *testing.T) {
+		assert.True(t, NewStreamFromSlice(data, 0).Concurrent(8).AllMatch(slowly(intGreaterThanPredicate(-1))))
+		assert.False(t, NewStreamFromSlice(data, 0).Concurrent(8).AllMatch(slowly(intGreaterThanPredicate(0))))
+		assert.True(t, NewStreamFromSlice(data, 0).Concurrent(8).AnyMatch(slowly(intGreaterThanPredicate(98))))
+		assert.False(t, NewStreamFromSlice(data, 0).Concurrent(8).AnyMatch(slowly(intGreaterThanPredicate(99))))
+	})
+}
+
+func TestStream_ForEachConcurrent(t *testing.T) {
+	const numEntries = 40
+
+	var running, maxRunning, sum atomic.Int32
+
+	consumer := func(i int) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+		}
+
+		time.Sleep(2 * time.Millisecond)
+		sum.Add(int32(i))
+	}
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	NewStreamFromSlice(data, 0).ForEachConcurrent(4, consumer)
+
+	assert.Equal(t, int32(numEntries*(numEntries-1)/2), sum.Load(), "all consumers should have completed")
+	assert.Equal(t, int32(0), running.Load())
+	assert.Greater(t, maxRunning.Load(), int32(1))
+	assert.LessOrEqual(t, maxRunning.Load(), int32(4))
+
+	assert.Panics(t, func() {
+		NewStreamFromSlice(data, 0).ForEachConcurrent(4, func(int) { panic("boom") })
+	})
+}
+
+func TestStream_ForEach_HonoursConcurrency(t *testing.T) {
+	const numEntries = 40
+
+	var running, maxRunning, sum atomic.Int32
+
+	consumer := func(i int) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+		}
+
+		time.Sleep(2 * time.Millisecond)
+		sum.Add(int32(i))
+	}
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	NewStreamFromSlice(data, 0).Concurrent(4).Unordered().ForEach(consumer)
+
+	assert.Equal(t, int32(numEntries*(numEntries-1)/2), sum.Load(), "all consumers should have completed")
+	assert.Equal(t, int32(0), running.Load())
+	assert.Greater(t, maxRunning.Load(), int32(1))
+	assert.LessOrEqual(t, maxRunning.Load(), int32(4))
+}
+
+func TestFromSlice(t *testing.T) {
+	s := FromSlice([]int{1, 2, 3})
+	assert.True(t, s.sliced)
+	assert.Equal(t, []int{1, 2, 3}, s.ToSlice())
+	assert.Equal(t, s.ToSlice(), NewStreamFromSlice([]int{1, 2, 3}, 10).ToSlice())
+}
+
+func TestStream_Lazy(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	var calls atomic.Int32
+
+	s := NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).
+		Filter(intGreaterThanPredicate(1)).
+		Concurrent(4).
+		Map(func(i int) Any {
+			calls.Add(1)
+			return i * 10
+		}).
+		Intersperse(0).
+		Take(5)
+
+	assert.Equal(t, numGoroutines, runtime.NumGoroutine(), "intermediate operations must not start goroutines")
+	assert.Zero(t, calls.Load())
+
+	assert.Equal(t, []Any{20, 0, 30, 0, 40}, s.ToSlice())
+	// a Stream created from a slice can be run again
+	assert.Equal(t, 5, s.Count())
+
+	c := make(chan int, 3)
+	c <- 1
+	c <- 2
+	c <- 3
+	close(c)
+
+	ch := NewStream(c).Filter(intGreaterThanPredicate(1)).Drop(1)
+	assert.Len(t, c, 3, "the channel must not be consumed before a terminal operation")
+	assert.Equal(t, []int{3}, ch.ToSlice())
+}
+
+func TestStream_RunsHaveTheirOwnErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	var runs atomic.Int32
+
+	failOnFirstRun := func(i int) (Any, error) {
+		if i == 1 && runs.Add(1) == 1 {
+			return nil, errBoom
+		}
+		return i, nil
+	}
+
+	s := NewStreamFromSlice([]int{1, 2, 3}, 0).MapE(failOnFirstRun)
+
+	got, err := s.ToSliceE()
+	assert.Equal(t, []Any{}, got)
+	assert.ErrorIs(t, err, errBoom)
+	assert.ErrorIs(t, s.Err(), errBoom)
+
+	got, err = s.ToSliceE()
+	assert.Equal(t, []Any{1, 2, 3}, got)
+	assert.NoError(t, err, "the error of the first run must not be reported by the second run")
+	assert.NoError(t, s.Err())
+
+	var panics atomic.Int32
+
+	panicOnFirstRun := func(i int) Any {
+		if i == 2 && panics.Add(1) == 1 {
+			panic(errBoom)
+		}
+		return i
+	}
+
+	p := NewStreamFromSlice([]int{1, 2, 3}, 0).Concurrent(2).Map(panicOnFirstRun)
+
+	assert.Panics(t, func() { p.ToSlice() })
+	assert.NotPanics(t, func() {
+		assert.Equal(t, []Any{1, 2, 3}, p.ToSlice())
+	}, "the panic of the first run must not be re-raised by the second run")
+}
+
+func TestStream_Err_IsScopedToTheRunsOfTheStream(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	alwaysFail := func(int) (Any, error) { return nil, errBoom }
+	succeed := func(i int) (Any, error) { return i, nil }
+
+	base := FromSlice([]int{1, 2, 3}).WithErrorPolicy(CollectErrors)
+	a := base.MapE(alwaysFail)
+	b := base.MapE(succeed)
+	c := a.Filter(func(Any) bool { return true })
+
+	a.ToSlice()
+	b.ToSlice()
+
+	assert.ErrorIs(t, a.Err(), errBoom, "running a sibling Stream must not overwrite the errors")
+	assert.NoError(t, b.Err())
+	assert.NoError(t, c.Err(), "a Stream that never ran has no errors")
+	assert.NoError(t, base.Err())
+
+	_, err := c.ToSliceE()
+	assert.ErrorIs(t, err, errBoom)
+	assert.ErrorIs(t, c.Err(), errBoom)
+	assert.ErrorIs(t, c.Concurrent(2).Err(), errBoom, "the copies of a Stream with other settings share its runs")
+}
+
+func TestStream_Fusion(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	maxGoroutines := 0
+	observe := func() {
+		if n := runtime.NumGoroutine(); n > maxGoroutines {
+			maxGoroutines = n
+		}
+	}
+
+	got := NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6}, 0).
+		Filter(func(i int) bool { observe(); return i > 1 }).
+		Filter(func(i int) bool { observe(); return i%2 == 0 }).
+		Map(func(i int) Any { observe(); return i * 10 }).
+		TakeWhile(func(e Any) bool { observe(); return e.(int) < 60 }).
+		ToSlice()
+
+	assert.Equal(t, []Any{20, 40}, got)
+	assert.Equal(t, numGoroutines, maxGoroutines, "sequential stages must run on the goroutine of the terminal operation")
+}
+
+// waitForGoroutines waits until the number of goroutines is down to n, failing the test
+// with msg if it takes longer than a second.
+//
+// note: assert.Eventually is not used since it runs the condition in its own goroutine.
+func waitForGoroutines(t *testing.T, n int, msg string) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > n; {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// unfused inserts a goroutine and a channel after the stage that produces s,
+// as was the case for every stage before operator fusion.
+func unfused[T any](s Stream[T], bufsize int) Stream[T] {
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		c := make(chan T, bufsize)
+
+		go func() {
+			defer close(c)
+
+			s.run(ctx, func(val T) bool {
+				return send(ctx.Done(), c, val)
+			})
+		}()
+
+		NewStream(c).run(ctx, yield)
+	})
+}
+
+func BenchmarkStream_Fusion(b *testing.B) {
+	const numEntries = 10000
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	isEven := func(i int) bool { return i%2 == 0 }
+	isNotMultipleOfThree := func(i int) bool { return i%3 != 0 }
+	timesTwo := func(i int) Any { return 2 * i }
+	lessThanMax := func(e Any) bool { return e.(int) < 2*numEntries }
+
+	b.Run("for-loop", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			got := []Any{}
+			for _, e := range data {
+				if !isEven(e) || !isNotMultipleOfThree(e) {
+					continue
+				}
+				if m := timesTwo(e); lessThanMax(m) {
+					got = append(got, m)
+				}
+			}
+		}
+	})
+
+	b.Run("fused", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_ = NewStreamFromSlice(data, 0).
+				Filter(isEven).
+				Filter(isNotMultipleOfThree).
+				Map(timesTwo).
+				TakeWhile(lessThanMax).
+				ToSlice()
+		}
+	})
+
+	for _, bufsize := range []int{0, 100} {
+		bufsize := bufsize
+
+		b.Run("channel-per-stage/bufsize="+strconv.Itoa(bufsize), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				s := unfused(NewStreamFromSlice(data, 0), bufsize)
+				s = unfused(s.Filter(isEven), bufsize)
+				s = unfused(s.Filter(isNotMultipleOfThree), bufsize)
+				_ = unfused(unfused(s.Map(timesTwo), bufsize).TakeWhile(lessThanMax), bufsize).ToSlice()
+			}
+		})
+	}
+}
+
+func TestStream_Batched(t *testing.T) {
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+
+	want := []Any{}
+	for _, i := range data {
+		if i%3 != 0 {
+			want = append(want, 2*i)
+		}
+	}
+
+	notMultipleOfThree := func(i int) bool { return i%3 != 0 }
+
+	got := NewStreamFromSlice(data, 0).Concurrent(4).Batched(7).
+		Filter(notMultipleOfThree).
+		Map(functionTimesTwo).
+		ToSlice()
+	assert.Equal(t, want, got)
+
+	got = NewStreamFromSlice(data, 0).Concurrent(4).Batched(7).Unordered().
+		Filter(notMultipleOfThree).
+		Map(functionTimesTwo).
+		ToSlice()
+	assert.ElementsMatch(t, want, got)
+
+	assert.Equal(t, []Any{0, 2, 4}, NewStreamFromSlice(data, 0).Concurrent(4).Batched(7).Map(functionTimesTwo).HeadN(3))
+
+	errOdd := errors.New("odd number")
+
+	got, err := NewStreamFromSlice(data, 0).Concurrent(4).Batched(7).
+		WithErrorPolicy(CollectErrors).
+		MapE(func(i int) (Any, error) {
+			if i%2 != 0 {
+				return nil, errOdd
+			}
+			return i, nil
+		}).
+		ToSliceE()
+	assert.Len(t, got, 50)
+	assert.ErrorIs(t, err, errOdd)
+}
+
+func BenchmarkStream_Batched(b *testing.B) {
+	const numEntries = 10000
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	timesTwo := func(i int) Any { return 2 * i }
+
+	b.Run("for-loop", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			got := []Any{}
+			for _, e := range data {
+				got = append(got, timesTwo(e))
+			}
+		}
+	})
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_ = NewStreamFromSlice(data, 0).Map(timesTwo).ToSlice()
+		}
+	})
+
+	for _, batch := range []int{0, 16, 256} {
+		batch := batch
+
+		b.Run("concurrency=4/batch="+strconv.Itoa(batch), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = NewStreamFromSlice(data, 0).Concurrent(4).Batched(batch).Map(timesTwo).ToSlice()
+			}
+		})
+	}
+}
+
+func TestStream_SliceBacked(t *testing.T) {
+	data := []int{1, 2, 3, 4, 5}
+
+	s := NewStreamFromSlice(data, 0)
+	assert.True(t, s.sliced)
+
+	for name, tc := range map[string]struct {
+		stream Stream[int]
+		want   []int
+	}{
+		"Drop":              {stream: s.Drop(2), want: []int{3, 4, 5}},
+		"Drop all":          {stream: s.Drop(10), want: []int{}},
+		"Take":              {stream: s.Take(2), want: []int{1, 2}},
+		"Take all":          {stream: s.Take(10), want: data},
+		"Intersperse":       {stream: s.Take(3).Intersperse(0), want: []int{1, 0, 2, 0, 3}},
+		"Intersperse empty": {stream: s.Take(0).Intersperse(0), want: []int{}},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, tc.stream.sliced)
+			assert.Equal(t, tc.want, tc.stream.ToSlice())
+			assert.Equal(t, len(tc.want), tc.stream.Count())
+		})
+	}
+
+	assert.False(t, s.Filter(True[int]()).sliced)
+	assert.Equal(t, []int{4, 5}, s.LastN(2))
+	assert.Equal(t, data, s.LastN(10))
+	assert.Equal(t, []int{1, 2}, s.HeadN(2))
+
+	got := s.ToSlice()
+	got[0] = 100
+	assert.Equal(t, 1, data[0], "ToSlice must return a copy of the slice")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Zero(t, s.WithContext(ctx).Count())
+}
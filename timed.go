@@ -0,0 +1,396 @@
+package fuego
+
+import (
+	"context"
+	"time"
+)
+
+// BufferTime returns a Stream of the elements of s grouped in slices of up to maxSize elements.
+//
+// A slice is published as soon as it holds maxSize elements or maxWait after its first element
+// was received, whichever comes first. The pending elements are published when the in-stream is
+// closed. The time is measured with the Clock of s (see Stream.WithClock).
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if maxSize < 1 or maxWait <= 0.
+func BufferTime[T any](s Stream[T], maxSize int, maxWait time.Duration) Stream[[]T] {
+	if maxSize < 1 || maxWait <= 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, func(ctx context.Context, yield func([]T) bool) {
+		if s.missingChannel() {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		in := s.materialise(ctx)
+		defer errorsOf(ctx).rethrow()
+
+		var (
+			batch   []T
+			timer   Timer
+			expired <-chan time.Time
+		)
+
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, expired = nil, nil
+			}
+
+			b := batch
+			batch = nil
+
+			return yield(b)
+		}
+
+		done := ctx.Done()
+
+		for {
+			select {
+			case val, ok := <-in.stream:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+
+					return
+				}
+
+				if batch = append(batch, val); len(batch) == 1 {
+					timer = s.Clock().NewTimer(maxWait)
+					expired = timer.C()
+				}
+
+				if len(batch) == maxSize && !flush() {
+					return
+				}
+
+			case <-expired:
+				if !flush() {
+					return
+				}
+
+			case <-done:
+				return
+			}
+		}
+	})
+}
+
+// Throttle returns a Stream that publishes the elements of this Stream at a rate of up to
+// rate elements per second, with bursts of up to burst elements.
+//
+// Throttle is a token bucket: the bucket holds up to burst tokens and is refilled at the given
+// rate. Each element takes a token and waits for one when the bucket is empty. The time is
+// measured with the Clock of this Stream (see WithClock).
+//
+// The operations downstream of Throttle share its limiter: with a concurrent Map downstream
+// (see Concurrent), the workers of Map are collectively limited to the rate.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if rate <= 0 or burst < 1.
+func (s Stream[T]) Throttle(rate float64, burst int) Stream[T] {
+	if rate <= 0 || burst < 1 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		bucket := newTokenBucket(s.Clock(), rate, burst)
+		done := ctx.Done()
+
+		s.run(ctx, func(val T) bool {
+			return bucket.take(done) && yield(val)
+		})
+	})
+}
+
+// Delay returns a Stream that publishes each element of this Stream d after it was received,
+// such that the gaps between the elements are preserved. The elements received in the meantime
+// are held until they are due. The time is measured with the Clock of this Stream (see WithClock).
+//
+// Delay does not space the elements out: see Throttle to limit their rate.
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too, once the pending elements are published.
+// Panics if d < 0.
+func (s Stream[T]) Delay(d time.Duration) Stream[T] {
+	if d < 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		if s.missingChannel() {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		in := s.materialise(ctx)
+		defer errorsOf(ctx).rethrow()
+
+		type delayed struct {
+			val T
+			due time.Time
+		}
+
+		var (
+			queue   []delayed
+			timer   Timer
+			expired <-chan time.Time
+		)
+
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		clock := s.Clock()
+		source := in.stream // nil once closed
+		done := ctx.Done()
+
+		for {
+			now := clock.Now()
+
+			for len(queue) > 0 && !queue[0].due.After(now) {
+				val := queue[0].val
+				queue[0] = delayed{}
+				queue = queue[1:]
+
+				if !yield(val) {
+					return
+				}
+			}
+
+			if len(queue) == 0 && source == nil {
+				return
+			}
+
+			if timer == nil && len(queue) > 0 {
+				timer = clock.NewTimer(queue[0].due.Sub(now))
+				expired = timer.C()
+			}
+
+			select {
+			case val, ok := <-source:
+				if !ok {
+					source = nil
+					continue
+				}
+
+				queue = append(queue, delayed{val: val, due: clock.Now().Add(d)})
+
+			case <-expired:
+				timer, expired = nil, nil
+
+			case <-done:
+				return
+			}
+		}
+	})
+}
+
+// Sample returns a Stream that publishes the latest element of this Stream at every interval,
+// provided a new element was received during the interval. The latest element is also published
+// when the in-stream is closed if it was not yet. The time is measured with the Clock of this
+// Stream (see WithClock).
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if interval <= 0.
+func (s Stream[T]) Sample(interval time.Duration) Stream[T] {
+	if interval <= 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		if s.missingChannel() {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		in := s.materialise(ctx)
+		defer errorsOf(ctx).rethrow()
+
+		var (
+			latest T
+			fresh  bool // latest was not published yet
+		)
+
+		clock := s.Clock()
+		timer := clock.NewTimer(interval)
+		done := ctx.Done()
+
+		defer func() { timer.Stop() }()
+
+		for {
+			select {
+			case val, ok := <-in.stream:
+				if !ok {
+					if fresh {
+						yield(latest)
+					}
+
+					return
+				}
+
+				latest, fresh = val, true
+
+			case <-timer.C():
+				timer = clock.NewTimer(interval)
+
+				if fresh {
+					if fresh = false; !yield(latest) {
+						return
+					}
+				}
+
+			case <-done:
+				return
+			}
+		}
+	})
+}
+
+// Debounce returns a Stream that publishes an element of this Stream only once no other element
+// was received for the quiet duration. The elements received in quick succession are thus
+// dropped in favour of the last of them. The pending element is published when the in-stream is
+// closed. The time is measured with the Clock of this Stream (see WithClock).
+//
+// This function streams continuously until the in-stream is closed at
+// which point the out-stream will be closed too.
+// Panics if quiet <= 0.
+func (s Stream[T]) Debounce(quiet time.Duration) Stream[T] {
+	if quiet <= 0 {
+		panic(PanicInvalidArgument)
+	}
+
+	return derive(s, func(ctx context.Context, yield func(T) bool) {
+		if s.missingChannel() {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		in := s.materialise(ctx)
+		defer errorsOf(ctx).rethrow()
+
+		var (
+			latest  T
+			timer   Timer
+			expired <-chan time.Time // nil unless an element is pending
+		)
+
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		clock := s.Clock()
+		done := ctx.Done()
+
+		for {
+			select {
+			case val, ok := <-in.stream:
+				if !ok {
+					if expired != nil {
+						yield(latest)
+					}
+
+					return
+				}
+
+				if timer != nil {
+					timer.Stop()
+				}
+
+				latest = val
+				timer = clock.NewTimer(quiet)
+				expired = timer.C()
+
+			case <-expired:
+				timer, expired = nil, nil
+
+				if !yield(latest) {
+					return
+				}
+
+			case <-done:
+				return
+			}
+		}
+	})
+}
+
+// tokenBucket is the token bucket limiter of Throttle.
+type tokenBucket struct {
+	clock  Clock
+	rate   float64 // tokens added per second
+	burst  float64 // capacity of the bucket
+	tokens float64
+	last   time.Time // time of the last refill
+}
+
+// newTokenBucket creates a full tokenBucket.
+func newTokenBucket(clock Clock, rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		clock:  clock,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// take takes a token from the bucket, waiting for one if the bucket is empty.
+// It returns false if done is closed first.
+func (b *tokenBucket) take(done <-chan struct{}) bool {
+	b.refill()
+
+	if b.tokens < 1 {
+		if !sleep(b.clock, time.Duration((1-b.tokens)/b.rate*float64(time.Second)), done) {
+			return false
+		}
+
+		b.refill()
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// refill adds the tokens accrued since the last refill.
+func (b *tokenBucket) refill() {
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// sleep waits for duration d measured with clock. It returns false if done is closed first.
+func sleep(clock Clock, d time.Duration, done <-chan struct{}) bool {
+	if d <= 0 {
+		return !cancelled(done)
+	}
+
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,241 @@
+package fuego
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// consume runs s in a new goroutine and publishes its elements to the returned channel.
+func consume[T any](s Stream[T]) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		s.ForEach(func(val T) { out <- val })
+	}()
+
+	return out
+}
+
+func TestBufferTime(t *testing.T) {
+	clock := newFakeClock()
+	c := make(chan int)
+
+	out := consume(BufferTime(NewStream(c).WithClock(clock), 3, time.Second))
+
+	// flushes when full
+	c <- 1
+	c <- 2
+	c <- 3
+	assert.Equal(t, []int{1, 2, 3}, <-out)
+
+	// flushes when maxWait elapsed
+	c <- 4
+	clock.blockUntil(1)
+	clock.advance(time.Second)
+	assert.Equal(t, []int{4}, <-out)
+
+	// maxWait is measured from the first element
+	c <- 5
+	clock.blockUntil(1)
+	clock.advance(500 * time.Millisecond)
+	c <- 6
+	clock.advance(500 * time.Millisecond)
+	assert.Equal(t, []int{5, 6}, <-out)
+
+	// flushes when closed
+	c <- 7
+	close(c)
+	assert.Equal(t, []int{7}, <-out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestBufferTime_Pipeline(t *testing.T) {
+	got := BufferTime(NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0).Filter(True[int]()), 2, time.Hour).ToSlice()
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, got)
+
+	assert.Equal(t, [][]int{}, BufferTime(Stream[int]{}, 2, time.Hour).ToSlice())
+	assert.Equal(t, [][]int{{1, 2}}, BufferTime(NewStreamFromSlice([]int{1, 2, 3, 4, 5}, 0), 2, time.Hour).HeadN(1))
+
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { BufferTime(Stream[int]{}, 0, time.Hour) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { BufferTime(Stream[int]{}, 1, 0) })
+}
+
+func TestStream_Throttle(t *testing.T) {
+	clock := newFakeClock()
+
+	out := consume(NewStreamFromSlice([]int{1, 2, 3, 4}, 0).WithClock(clock).Throttle(1, 2))
+
+	// the burst
+	assert.Equal(t, 1, <-out)
+	assert.Equal(t, 2, <-out)
+
+	clock.blockUntil(1)
+	clock.advance(time.Second)
+	assert.Equal(t, 3, <-out)
+
+	clock.blockUntil(1)
+	clock.advance(500 * time.Millisecond)
+	clock.advance(500 * time.Millisecond)
+	assert.Equal(t, 4, <-out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Stream[int]{}.Throttle(0, 1) })
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Stream[int]{}.Throttle(1, 0) })
+}
+
+func TestStream_Throttle_SharedByConcurrentMap(t *testing.T) {
+	clock := newFakeClock()
+
+	var calls atomic.Int32
+
+	out := consume(NewStreamFromSlice([]int{1, 2, 3}, 0).
+		WithClock(clock).
+		Throttle(1, 1).
+		Concurrent(4).
+		Map(func(i int) Any {
+			calls.Add(1)
+			return i
+		}))
+
+	assert.Equal(t, 1, <-out)
+	clock.blockUntil(1)
+	assert.Equal(t, int32(1), calls.Load())
+
+	clock.advance(time.Second)
+	assert.Equal(t, 2, <-out)
+	clock.blockUntil(1)
+	assert.Equal(t, int32(2), calls.Load())
+
+	clock.advance(time.Second)
+	assert.Equal(t, 3, <-out)
+}
+
+func TestStream_Throttle_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	clock := newFakeClock()
+	out := consume(NewStreamFromSlice([]int{1, 2, 3}, 0).WithContext(ctx).WithClock(clock).Throttle(1, 1))
+
+	assert.Equal(t, 1, <-out)
+	clock.blockUntil(1)
+	cancel()
+
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestStream_Delay(t *testing.T) {
+	clock := newFakeClock()
+	c := make(chan int)
+
+	out := consume(NewStream(c).WithClock(clock).Delay(time.Second).Take(2))
+
+	c <- 1
+	clock.blockUntil(1)
+	clock.advance(500 * time.Millisecond)
+
+	// 2 is received half a second after 1 and published half a second after it.
+	// 3 only makes sure that 2 was received before the time moves on.
+	c <- 2
+	c <- 3
+
+	clock.advance(500 * time.Millisecond)
+	assert.Equal(t, 1, <-out)
+
+	clock.blockUntil(1)
+	clock.advance(500 * time.Millisecond)
+	assert.Equal(t, 2, <-out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+
+	assert.Equal(t, []int{1, 2}, NewStreamFromSlice([]int{1, 2}, 0).Delay(0).ToSlice())
+	assert.Equal(t, []int{}, Stream[int]{}.Delay(time.Second).ToSlice())
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Stream[int]{}.Delay(-1) })
+}
+
+func TestStream_Delay_DoesNotSpaceTheElementsOut(t *testing.T) {
+	in := make([]int, 100)
+	for i := range in {
+		in[i] = i
+	}
+
+	start := time.Now()
+
+	assert.Equal(t, in, FromSlice(in).Delay(50*time.Millisecond).ToSlice())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestStream_Sample(t *testing.T) {
+	clock := newFakeClock()
+	c := make(chan int)
+
+	out := consume(NewStream(c).WithClock(clock).Sample(time.Second))
+
+	c <- 1
+	c <- 2
+	clock.blockUntil(1)
+	clock.advance(time.Second)
+	assert.Equal(t, 2, <-out)
+
+	// nothing is published without a new element
+	clock.blockUntil(1)
+	clock.advance(time.Second)
+	clock.blockUntil(1)
+
+	c <- 3
+	clock.advance(time.Second)
+	assert.Equal(t, 3, <-out)
+
+	// the latest element is published on close
+	clock.blockUntil(1)
+	c <- 4
+	close(c)
+	assert.Equal(t, 4, <-out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+
+	assert.Equal(t, []int{}, Stream[int]{}.Sample(time.Second).ToSlice())
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Stream[int]{}.Sample(0) })
+}
+
+func TestStream_Debounce(t *testing.T) {
+	clock := newFakeClock()
+	c := make(chan int)
+
+	out := consume(NewStream(c).WithClock(clock).Debounce(time.Second))
+
+	// a burst: each element restarts the quiet period
+	c <- 1
+	clock.blockUntilCreated(1)
+	clock.advance(500 * time.Millisecond)
+	c <- 2
+	clock.blockUntilCreated(2)
+	clock.advance(500 * time.Millisecond)
+	c <- 3
+	clock.blockUntilCreated(3)
+	clock.advance(time.Second)
+	assert.Equal(t, 3, <-out)
+
+	// the pending element is published on close
+	c <- 4
+	close(c)
+	assert.Equal(t, 4, <-out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+
+	assert.Equal(t, []int{}, Stream[int]{}.Debounce(time.Second).ToSlice())
+	assert.PanicsWithValue(t, PanicInvalidArgument, func() { Stream[int]{}.Debounce(0) })
+}
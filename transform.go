@@ -0,0 +1,79 @@
+package fuego
+
+// This file provides typed counterparts of the Stream methods that would need
+// parameterised methods, such as Stream.Map which returns a Stream[Any].
+// See doc.go for more details.
+
+// Map returns a Stream consisting of the result of applying the given function to the elements of s.
+//
+// Map is the typed counterpart of Stream.Map: it runs concurrently in accordance with the Stream's
+// concurrency level and the order of the elements is preserved, unless the Stream is Unordered.
+func Map[T, R any](s Stream[T], mapper Function[T, R]) Stream[R] {
+	return orderlyConcurrentDo(s, func(val T) (R, error) {
+		return mapper(val), nil
+	})
+}
+
+// FlatMap takes a StreamFunction to flatten the elements of s and produce a new Stream.
+//
+// FlatMap is the typed counterpart of Stream.FlatMap: it runs concurrently in accordance with the
+// Stream's concurrency level, as do the pipelines of the Streams returned by mapper, and the order
+// of the elements is preserved, unless the Stream is Unordered.
+func FlatMap[T, R any](s Stream[T], mapper StreamFunction[T, R]) Stream[R] {
+	return orderlyConcurrentDoStream(s, func(val T) (Stream[R], error) {
+		return mapper(val), nil
+	})
+}
+
+// Fold accumulates the elements of s into identity by applying the given function, from left to right.
+//
+// Unlike Stream.LeftReduce, the result may be of a different type than the elements and an empty
+// Stream yields identity.
+//
+// This is a continuous terminal operation. It will only complete if the producer closes the stream.
+func Fold[T, R any](s Stream[T], identity R, f2 BiFunction[R, T, R]) R {
+	s = s.start()
+
+	defer s.terminate()
+
+	res := identity
+	errs := errorsOf(s.Context())
+
+	s.each(func(val T) bool {
+		if err := try(func() { res = f2(res, val) }); err != nil {
+			return errs.report(err)
+		}
+
+		return true
+	})
+
+	return res
+}
+
+// GroupBy groups the elements of s by the key returned by the classifier.
+//
+// GroupBy is the typed counterpart of Stream.GroupBy.
+//
+// This is a continuous terminal operation and hence expects the producer to close the stream
+// in order to complete.
+func GroupBy[T any, K comparable](s Stream[T], classifier Function[T, K]) map[K][]T {
+	s = s.start()
+
+	defer s.terminate()
+
+	resultMap := make(map[K][]T)
+	errs := errorsOf(s.Context())
+
+	s.each(func(val T) bool {
+		var k K
+		if err := try(func() { k = classifier(val) }); err != nil {
+			return errs.report(err)
+		}
+
+		resultMap[k] = append(resultMap[k], val)
+
+		return true
+	})
+
+	return resultMap
+}
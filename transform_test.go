@@ -0,0 +1,55 @@
+package fuego
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	tt := map[string]struct {
+		stream Stream[int]
+		want   []string
+	}{
+		"Should return an empty Stream when nil channel": {
+			stream: Stream[int]{},
+			want:   []string{},
+		},
+		"Should map the elements": {
+			stream: NewStreamFromSlice([]int{1, 2, 3}, 0),
+			want:   []string{"1", "2", "3"},
+		},
+		"Should map the elements concurrently in order": {
+			stream: NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6}, 0).Concurrent(3),
+			want:   []string{"1", "2", "3", "4", "5", "6"},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Map(tc.stream, strconv.Itoa).ToSlice())
+		})
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	got := FlatMap(
+		NewStreamFromSlice([][]int{{1, 2}, {}, {3}}, 0),
+		FlattenTypedSlice[int](0),
+	).ToSlice()
+	assert.Equal(t, []int{1, 2, 3}, got)
+}
+
+func TestFold(t *testing.T) {
+	appendDigit := func(acc string, i int) string { return acc + strconv.Itoa(i) }
+
+	assert.Equal(t, ">123", Fold(NewStreamFromSlice([]int{1, 2, 3}, 0), ">", appendDigit))
+	assert.Equal(t, ">", Fold(NewStreamFromSlice([]int{}, 0), ">", appendDigit))
+}
+
+func TestGroupBy(t *testing.T) {
+	got := GroupBy(NewStreamFromSlice([]string{"a", "bb", "cc", "ddd"}, 0), func(s string) int { return len(s) })
+	assert.Equal(t, map[int][]string{1: {"a"}, 2: {"bb", "cc"}, 3: {"ddd"}}, got)
+}
@@ -0,0 +1,15 @@
+package fuego
+
+// Tuple2 is a pair of values.
+type Tuple2[A, B any] struct {
+	E1 A
+	E2 B
+}
+
+// NewTuple2 creates a new Tuple2.
+func NewTuple2[A, B any](e1 A, e2 B) Tuple2[A, B] {
+	return Tuple2[A, B]{
+		E1: e1,
+		E2: e2,
+	}
+}
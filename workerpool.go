@@ -0,0 +1,390 @@
+package fuego
+
+import (
+	"context"
+	"sync"
+)
+
+// job is an element of a Stream tagged with its position in the Stream.
+type job[T any] struct {
+	seq uint64
+	val T
+}
+
+// result holds the outcome of a fallible function.
+type result[T any] struct {
+	val T
+	err error
+}
+
+// call applies fn to val. A panic raised by fn is converted to a *PanicError.
+func call[T, U any](fn FunctionE[T, U], val T) (res result[U]) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = result[U]{err: newPanicError(r)}
+		}
+	}()
+
+	u, err := fn(val)
+
+	return result[U]{val: u, err: err}
+}
+
+// try calls fn. A panic raised by fn is converted to a *PanicError, which is returned.
+//
+// The functions that the operations of a Stream apply to its elements are called with call or
+// try, whether the operation is concurrent or not, and their errors are reported to the error
+// sink of the run: a panic is hence re-raised as a *PanicError by the terminal operation, or
+// handled as an error when the pipeline propagates errors.
+func try(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+// poolDo applies fn to the elements of s with a fixed pool of workers.
+//
+// The size of the pool is the concurrency level of s (with a minimum of 1).
+// The results are passed to emit in the order of the elements of s. To this end,
+// a reorder buffer holds the results that complete ahead of their predecessors.
+// The number of elements in flight is bounded, which also bounds the reorder buffer.
+//
+// When s is unordered, the results are passed to emit in completion order instead.
+//
+// The pipeline of s runs on the goroutine that dispatches the elements to the workers.
+// A panic raised by the pipeline is passed to emit as a *PanicError after the results
+// of the elements that preceded it.
+//
+// poolDo returns when s is exhausted, when ctx is cancelled or when emit returns false.
+// The goroutines of the pool, and hence the calls to fn and the pipeline of s, have
+// completed when poolDo returns.
+func poolDo[T, U any](ctx context.Context, s Stream[T], fn FunctionE[T, U], emit func(result[U]) bool) {
+	pool(ctx, s.concurrency, s.unordered, s.run, fn, emit)
+}
+
+// pool is poolDo over the elements published by source.
+//
+// pool does not depend on Stream so that it can be instantiated with batches of
+// elements without instantiating a Stream of batches: the methods of Stream[T]
+// cannot instantiate Stream[[]T] since Go would need to instantiate Stream[[][]T]
+// and so forth.
+func pool[T, U any](
+	ctx context.Context,
+	workers int,
+	unordered bool,
+	source func(ctx context.Context, yield func(T) bool),
+	fn FunctionE[T, U],
+	emit func(result[U]) bool,
+) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	window := 2 * workers // maximum number of elements in flight
+
+	jobs := make(chan job[T], workers)
+	results := make(chan job[result[U]], workers)
+	inFlight := make(chan struct{}, window)
+
+	// all tracks the dispatcher and the workers: they are stopped and waited for on return
+	// so that neither fn nor the pipeline runs after pool returns.
+	var all sync.WaitGroup
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer all.Wait()
+	defer cancel()
+
+	quit := ctx.Done()
+
+	var panicked *PanicError
+
+	// dispatcher
+	all.Add(1)
+
+	go func() {
+		defer all.Done()
+		defer close(jobs)
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = newPanicError(r)
+			}
+		}()
+
+		seq := uint64(0)
+
+		source(ctx, func(val T) bool {
+			if !send(quit, inFlight, struct{}{}) || !send(quit, jobs, job[T]{seq: seq, val: val}) {
+				return false
+			}
+
+			seq++
+
+			return true
+		})
+	}()
+
+	// workers
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	all.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer all.Done()
+			defer wg.Done()
+
+			for j := range jobs {
+				if !send(quit, results, job[result[U]]{seq: j.seq, val: call(fn, j.val)}) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if unordered {
+		for r := range results {
+			if !emit(r.val) {
+				return
+			}
+
+			<-inFlight
+		}
+
+		emitPanic(panicked, emit)
+
+		return
+	}
+
+	// reorder buffer
+	pending := make([]result[U], window)
+	ready := make([]bool, window)
+	next := uint64(0)
+
+	for r := range results {
+		idx := r.seq % uint64(window)
+		pending[idx], ready[idx] = r.val, true
+
+		for idx = next % uint64(window); ready[idx]; idx = next % uint64(window) {
+			res := pending[idx]
+			pending[idx], ready[idx] = result[U]{}, false
+
+			if !emit(res) {
+				return
+			}
+
+			<-inFlight
+			next++
+		}
+	}
+
+	emitPanic(panicked, emit)
+}
+
+// emitPanic passes p to emit as the error of a result, unless p is nil.
+func emitPanic[U any](p *PanicError, emit func(result[U]) bool) {
+	if p != nil {
+		emit(result[U]{err: p})
+	}
+}
+
+// shardDo applies fn to the elements of s with a fixed pool of workers where each
+// worker owns a shard of the elements.
+//
+// The elements are assigned to a shard by their hash. Each worker processes its shard
+// sequentially, which preserves the order of the elements that share a hash. The
+// results of different shards are passed to emit in completion order.
+//
+// The number of shards is the concurrency level of s (with a minimum of 1).
+// As with poolDo, a panic raised by the pipeline of s is passed to emit last.
+//
+// shardDo returns when s is exhausted, when ctx is cancelled or when emit returns false.
+// As with poolDo, its goroutines have completed when it returns.
+func shardDo[T, U any](ctx context.Context, s Stream[T], hashFn func(T) uint32, fn FunctionE[T, U], emit func(result[U]) bool) {
+	workers := s.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	shards := make([]chan T, workers)
+	results := make(chan result[U], workers)
+
+	// all tracks the dispatcher and the workers, which are waited for on return (see pool).
+	var all sync.WaitGroup
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer all.Wait()
+	defer cancel()
+
+	quit := ctx.Done()
+
+	var panicked *PanicError
+
+	for i := range shards {
+		shards[i] = make(chan T, workers)
+	}
+
+	// dispatcher
+	all.Add(1)
+
+	go func() {
+		defer all.Done()
+		defer func() {
+			for _, shard := range shards {
+				close(shard)
+			}
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = newPanicError(r)
+			}
+		}()
+
+		s.run(ctx, func(val T) bool {
+			return send(quit, shards[hashFn(val)%uint32(workers)], val)
+		})
+	}()
+
+	// workers
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	all.Add(workers)
+
+	for _, shard := range shards {
+		go func(shard <-chan T) {
+			defer all.Done()
+			defer wg.Done()
+
+			for val := range shard {
+				if !send(quit, results, call(fn, val)) {
+					return
+				}
+			}
+		}(shard)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		if !emit(r) {
+			return
+		}
+	}
+
+	emitPanic(panicked, emit)
+}
+
+// do applies fn to the elements of s and passes the results to emit, until s is
+// exhausted, ctx is cancelled or emit returns false.
+//
+// When s is concurrent, fn is executed by a pool of workers (see poolDo). Otherwise, fn
+// is fused with the stages of the pipeline of s: it runs on the calling goroutine and
+// no channel is involved.
+func do[T, U any](ctx context.Context, s Stream[T], fn FunctionE[T, U], emit func(result[U]) bool) {
+	if s.concurrency < 1 {
+		s.run(ctx, func(val T) bool {
+			return emit(call(fn, val))
+		})
+
+		return
+	}
+
+	if s.batch > 1 {
+		batchDo(ctx, s, fn, emit)
+		return
+	}
+
+	poolDo(ctx, s, fn, emit)
+}
+
+// batchDo is poolDo where the elements of s are dispatched to the workers in batches
+// of up to s.batch elements (see Stream.Batched).
+func batchDo[T, U any](ctx context.Context, s Stream[T], fn FunctionE[T, U], emit func(result[U]) bool) {
+	apply := func(batch []T) ([]result[U], error) {
+		results := make([]result[U], len(batch))
+		for i, val := range batch {
+			results[i] = call(fn, val)
+		}
+
+		return results, nil
+	}
+
+	pool(ctx, s.concurrency, s.unordered, batches(s.run, s.batch), apply, func(res result[[]result[U]]) bool {
+		if res.err != nil {
+			return emit(result[U]{err: res.err})
+		}
+
+		for _, r := range res.val {
+			if !emit(r) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// batches returns a source of the elements published by source grouped in slices of up to n elements.
+func batches[T any](source func(ctx context.Context, yield func(T) bool), n int) func(ctx context.Context, yield func([]T) bool) {
+	return func(ctx context.Context, yield func([]T) bool) {
+		batch := make([]T, 0, n)
+		open := true
+
+		source(ctx, func(val T) bool {
+			if batch = append(batch, val); len(batch) < n {
+				return true
+			}
+
+			open = yield(batch)
+			batch = make([]T, 0, n)
+
+			return open
+		})
+
+		if open && len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// evaluated holds an element of a Stream along with the value a function returned for it.
+type evaluated[T, U any] struct {
+	elem T
+	val  U
+}
+
+// eachDo calls fn for each element of s, then emit with the element and the value returned by fn,
+// until s is exhausted or emit returns false.
+//
+// fn is executed as per do. emit is always called sequentially and in the order of the
+// elements, unless s is Unordered.
+// The errors returned by fn, including its panics (see PanicError), are reported to the error sink of ctx.
+func eachDo[T, U any](ctx context.Context, s Stream[T], fn FunctionE[T, U], emit func(T, U) bool) {
+	errs := errorsOf(ctx)
+
+	evaluate := func(val T) (evaluated[T, U], error) {
+		u, err := fn(val)
+		return evaluated[T, U]{elem: val, val: u}, err
+	}
+
+	do(ctx, s, evaluate, func(res result[evaluated[T, U]]) bool {
+		if res.err != nil {
+			return errs.report(res.err)
+		}
+
+		return emit(res.val.elem, res.val.val)
+	})
+}
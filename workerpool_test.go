@@ -0,0 +1,249 @@
+package fuego
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolDo_PreservesOrder(t *testing.T) {
+	const numEntries = 200
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	slowTimesTwo := func(i int) (int, error) {
+		time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond) //nolint:gosec
+		return 2 * i, nil
+	}
+
+	got := []int{}
+	poolDo(context.Background(), NewStreamFromSlice(data, 0).Concurrent(8), slowTimesTwo, func(r result[int]) bool {
+		got = append(got, r.val)
+		return true
+	})
+
+	want := make([]int, numEntries)
+	for i := range want {
+		want[i] = 2 * i
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestPoolDo_BoundsWorkers(t *testing.T) {
+	const workers = 4
+
+	var running, maxRunning atomic.Int32
+
+	fn := func(i int) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+		}
+
+		time.Sleep(time.Millisecond)
+
+		return i, nil
+	}
+
+	count := 0
+	poolDo(context.Background(), NewStreamFromSlice(make([]int, 100), 0).Concurrent(workers), fn, func(result[int]) bool {
+		count++
+		return true
+	})
+
+	assert.Equal(t, 100, count)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(workers))
+}
+
+func TestPoolDo_StopsWhenEmitReturnsFalse(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	s := NewStreamFromSlice(make([]int, 1000), 0).Concurrent(4)
+
+	got := []int{}
+	poolDo(context.Background(), s,
+		func(i int) (int, error) { return i, nil },
+		func(r result[int]) bool {
+			got = append(got, r.val)
+			return len(got) < 3
+		})
+
+	assert.Len(t, got, 3)
+
+	waitForGoroutines(t, numGoroutines, "worker pool goroutines were not released")
+}
+
+func TestPoolDo_WaitsForTheWorkers(t *testing.T) {
+	var running atomic.Int32
+
+	slowTimesTwo := func(i int) (int, error) {
+		running.Add(1)
+		defer running.Add(-1)
+
+		time.Sleep(20 * time.Millisecond)
+
+		return 2 * i, nil
+	}
+
+	poolDo(context.Background(), NewStreamFromSlice(make([]int, 100), 0).Concurrent(4), slowTimesTwo, func(result[int]) bool {
+		return false
+	})
+
+	assert.Zero(t, running.Load(), "a mapper is still running")
+
+	got := NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8}, 0).
+		Concurrent(4).
+		Map(func(i int) Any {
+			_, _ = slowTimesTwo(i)
+			return i
+		}).
+		HeadN(1)
+
+	assert.Equal(t, []Any{1}, got)
+	assert.Zero(t, running.Load(), "a mapper is still running")
+}
+
+func TestPoolDo_ForwardsPipelinePanics(t *testing.T) {
+	s := derive(NewStreamFromSlice([]int{1, 2, 3, 4}, 0), func(ctx context.Context, yield func(int) bool) {
+		for i := 1; i < 3; i++ {
+			yield(i)
+		}
+		panic("boom")
+	}).Concurrent(2)
+
+	got := []result[int]{}
+	poolDo(context.Background(), s, func(i int) (int, error) { return i, nil }, func(r result[int]) bool {
+		got = append(got, r)
+		return true
+	})
+
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, []result[int]{{val: 1}, {val: 2}}, got[:2])
+		assert.IsType(t, &PanicError{}, got[2].err)
+		assert.Equal(t, "boom", got[2].err.(*PanicError).Value)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	got := [][]int{}
+	batches(NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6, 7}, 0).run, 3)(context.Background(), func(batch []int) bool {
+		got = append(got, batch)
+		return true
+	})
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, got)
+
+	got = [][]int{}
+	batches(NewStreamFromSlice([]int{1, 2, 3, 4, 5, 6, 7}, 0).run, 3)(context.Background(), func(batch []int) bool {
+		got = append(got, batch)
+		return false
+	})
+	assert.Equal(t, [][]int{{1, 2, 3}}, got)
+}
+
+func TestCall_RecoversPanics(t *testing.T) {
+	res := call(func(i int) (int, error) { panic("boom") }, 1)
+	assert.IsType(t, &PanicError{}, res.err)
+	assert.Equal(t, "boom", res.err.(*PanicError).Value)
+}
+
+// goroutinePerElementDo is the concurrent engine that preceded poolDo.
+// It is kept for benchmarking purposes.
+func goroutinePerElementDo[T, U any](s Stream[T], fn Function[T, U], emit func(U)) {
+	pipelineCh := make(chan chan U, s.concurrency)
+
+	go func() {
+		defer close(pipelineCh)
+
+		s.each(func(val T) bool {
+			resultCh := make(chan U, 1)
+			pipelineCh <- resultCh
+
+			go func(resultCh chan<- U, val T) {
+				defer close(resultCh)
+				resultCh <- fn(val)
+			}(resultCh, val)
+
+			return true
+		})
+	}()
+
+	for resultCh := range pipelineCh {
+		emit(<-resultCh)
+	}
+}
+
+func BenchmarkConcurrentDo(b *testing.B) {
+	const numEntries = 1000
+
+	data := make([]int, numEntries)
+	for i := range data {
+		data[i] = i
+	}
+
+	timesTwo := func(i int) int { return 2 * i }
+
+	latentTimesTwo := func(i int) int {
+		time.Sleep(10 * time.Microsecond)
+		return 2 * i
+	}
+
+	for _, fn := range []struct {
+		name string
+		fn   Function[int, int]
+	}{
+		{name: "cheap", fn: timesTwo},
+		{name: "latent", fn: latentTimesTwo},
+	} {
+		for _, concurrency := range []int{1, 4, 16, 64} {
+			fn := fn
+			concurrency := concurrency
+
+			b.Run(fn.name+"/goroutine-per-element/concurrency="+strconv.Itoa(concurrency), func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					goroutinePerElementDo(NewStreamFromSlice(data, 100).Concurrent(concurrency), fn.fn, func(int) {})
+				}
+			})
+
+			b.Run(fn.name+"/worker-pool/concurrency="+strconv.Itoa(concurrency), func(b *testing.B) {
+				b.ReportAllocs()
+
+				fnE := func(i int) (int, error) { return fn.fn(i), nil }
+
+				for i := 0; i < b.N; i++ {
+					poolDo(context.Background(), NewStreamFromSlice(data, 100).Concurrent(concurrency), fnE, func(result[int]) bool { return true })
+				}
+			})
+		}
+	}
+}
+
+func TestPoolDo_Unordered(t *testing.T) {
+	slowFirst := func(i int) (int, error) {
+		if i == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return i, nil
+	}
+
+	got := []int{}
+	poolDo(context.Background(), NewStreamFromSlice([]int{0, 1, 2, 3, 4, 5}, 0).Concurrent(3).Unordered(), slowFirst, func(r result[int]) bool {
+		got = append(got, r.val)
+		return true
+	})
+
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5}, got)
+	assert.NotEqual(t, 0, got[0], "the slow element should not hold back the others")
+}
@@ -0,0 +1,122 @@
+package fuego
+
+import (
+	"context"
+	"iter"
+)
+
+// Zip returns a Stream of the pairs formed by the elements of a and b, by position.
+//
+// The out-stream is closed as soon as either a or b is closed, at which point the
+// other Stream is stopped. The out-stream inherits the settings of a.
+func Zip[A, B any](a Stream[A], b Stream[B]) Stream[Tuple2[A, B]] {
+	return ZipWith(a, b, NewTuple2[A, B])
+}
+
+// ZipWith returns a Stream consisting of the result of applying the given function to the
+// elements of a and b, by position.
+//
+// The out-stream is closed as soon as either a or b is closed, at which point the
+// other Stream is stopped. The out-stream inherits the settings of a.
+// The errors and panics of b are handled by the out-stream, in accordance with its error policy.
+func ZipWith[A, B, R any](a Stream[A], b Stream[B], f BiFunction[A, B, R]) Stream[R] {
+	return derive(a, func(ctx context.Context, yield func(R) bool) {
+		next, stop := pull(ctx, b)
+		defer stop()
+
+		errs := errorsOf(ctx)
+
+		a.run(ctx, func(x A) bool {
+			y, ok := next()
+			if !ok {
+				return false
+			}
+
+			var r R
+			if err := try(func() { r = f(x, y) }); err != nil {
+				return errs.report(err)
+			}
+
+			return yield(r)
+		})
+	})
+}
+
+// ZipLongest returns a Stream of the pairs formed by the elements of a and b, by position.
+// The shorter Stream is padded with empty Optionals.
+//
+// The out-stream is closed when both a and b are closed. The out-stream inherits the settings of a.
+// The errors and panics of b are handled by the out-stream, in accordance with its error policy.
+func ZipLongest[A, B any](a Stream[A], b Stream[B]) Stream[Tuple2[Optional[A], Optional[B]]] {
+	return derive(a, func(ctx context.Context, yield func(Tuple2[Optional[A], Optional[B]]) bool) {
+		next, stop := pull(ctx, b)
+		defer stop()
+
+		more := true // b has more elements
+		stopped := false
+
+		a.run(ctx, func(x A) bool {
+			y := OptionalEmpty[B]()
+
+			if more {
+				var val B
+				if val, more = next(); more {
+					y = OptionalOf(val)
+				}
+			}
+
+			stopped = !yield(NewTuple2(OptionalOf(x), y))
+
+			return !stopped
+		})
+
+		if stopped || cancelled(ctx.Done()) {
+			return
+		}
+
+		for more {
+			var val B
+			if val, more = next(); more && !yield(NewTuple2(OptionalEmpty[A](), OptionalOf(val))) {
+				return
+			}
+		}
+	})
+}
+
+// Unzip returns two Streams consisting of the first and the second elements of the pairs of s.
+//
+// The pipeline of s runs once, when the first of the Streams is run, and the pairs are published
+// to both Streams as with Tee: the buffer size of the Streams and the BackPressure policy are set
+// with WithBackPressure. Each of the Streams can only be run once. When one of the Streams stops,
+// the other carries on alone.
+//
+// Under the default SpillWhenFull policy, the Streams can be consumed one after the other.
+// Under the SlowestGoverns policy, they must be consumed concurrently: consuming them one
+// after the other deadlocks once the buffer of the other Stream is full.
+func Unzip[A, B any](s Stream[Tuple2[A, B]]) (Stream[A], Stream[B]) {
+	streams := fanOut(s, 2, func(Tuple2[A, B]) int { return broadcast })
+
+	first := derive(streams[0], func(ctx context.Context, yield func(A) bool) {
+		streams[0].run(ctx, func(t Tuple2[A, B]) bool {
+			return yield(t.E1)
+		})
+	})
+
+	second := derive(streams[1], func(ctx context.Context, yield func(B) bool) {
+		streams[1].run(ctx, func(t Tuple2[A, B]) bool {
+			return yield(t.E2)
+		})
+	})
+
+	return first, second
+}
+
+// pull returns a pull iterator over the elements of s, run with ctx.
+// The iterator must be stopped to release the pipeline of s.
+func pull[T any](ctx context.Context, s Stream[T]) (func() (T, bool), func()) {
+	return iter.Pull(func(yield func(T) bool) {
+		defer errorsOf(ctx).rethrow()
+
+		s.run(ctx, yield)
+	})
+}
@@ -0,0 +1,249 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZip(t *testing.T) {
+	tt := map[string]struct {
+		a    Stream[int]
+		b    Stream[string]
+		want []Tuple2[int, string]
+	}{
+		"Should pair the elements by position": {
+			a:    NewStreamFromSlice([]int{1, 2, 3}, 0),
+			b:    NewStreamFromSlice([]string{"a", "b", "c"}, 0),
+			want: []Tuple2[int, string]{{1, "a"}, {2, "b"}, {3, "c"}},
+		},
+		"Should stop when the first Stream closes": {
+			a:    NewStreamFromSlice([]int{1}, 0),
+			b:    NewStreamFromSlice([]string{"a", "b", "c"}, 0),
+			want: []Tuple2[int, string]{{1, "a"}},
+		},
+		"Should stop when the second Stream closes": {
+			a:    NewStreamFromSlice([]int{1, 2, 3}, 0),
+			b:    NewStreamFromSlice([]string{"a", "b"}, 0),
+			want: []Tuple2[int, string]{{1, "a"}, {2, "b"}},
+		},
+		"Should return an empty Stream when nil channel": {
+			a:    Stream[int]{},
+			b:    NewStreamFromSlice([]string{"a", "b"}, 0),
+			want: []Tuple2[int, string]{},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Zip(tc.a, tc.b).ToSlice())
+		})
+	}
+}
+
+func TestZip_StopsTheOtherStream(t *testing.T) {
+	numGoroutines := runtime.NumGoroutine()
+
+	naturals := func() Stream[int] {
+		return FromSeq(func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		})
+	}
+
+	assert.Equal(t, []Tuple2[int, int]{{0, 0}, {1, 1}}, Zip(naturals().Take(2), naturals()).ToSlice())
+	assert.Equal(t, []Tuple2[int, int]{{0, 0}, {1, 1}}, Zip(naturals(), naturals().Take(2)).ToSlice())
+	assert.Equal(t, []Tuple2[int, int]{{0, 0}}, Zip(naturals(), naturals()).HeadN(1))
+
+	waitForGoroutines(t, numGoroutines, "the other Stream was not stopped")
+}
+
+func TestZip_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := make(chan int)
+	b := make(chan int)
+
+	go func() {
+		a <- 1
+		b <- 1
+		cancel()
+	}()
+
+	got := Zip(NewStream(a).WithContext(ctx), NewStream(b)).ToSlice()
+	assert.Equal(t, []Tuple2[int, int]{{1, 1}}, got)
+}
+
+func TestZipWith(t *testing.T) {
+	got := ZipWith(
+		NewStreamFromSlice([]int{1, 2, 3}, 0),
+		NewStreamFromSlice([]string{"a", "b"}, 0),
+		func(i int, s string) string { return s + strconv.Itoa(i) },
+	).ToSlice()
+
+	assert.Equal(t, []string{"a1", "b2"}, got)
+}
+
+func TestZip_ReportsTheErrorsOfTheSecondStream(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	failing := func() Stream[int] {
+		return FromSlice([]int{1, 2, 3}).FilterE(func(i int) (bool, error) {
+			if i == 2 {
+				return false, errBoom
+			}
+			return true, nil
+		})
+	}
+
+	panicking := func() Stream[int] {
+		return FromSlice([]int{1, 2, 3}).Peek(func(i int) {
+			if i == 2 {
+				panic("boom")
+			}
+		})
+	}
+
+	tt := map[string]struct {
+		zip func(b Stream[int]) error
+	}{
+		"ZipWith": {
+			zip: func(b Stream[int]) error {
+				_, err := ZipWith(FromSlice([]int{1, 2, 3}), b, func(x, y int) int { return x + y }).ToSliceE()
+				return err
+			},
+		},
+		"ZipLongest": {
+			zip: func(b Stream[int]) error {
+				_, err := ZipLongest(FromSlice([]int{1, 2, 3}), b).ToSliceE()
+				return err
+			},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+
+		t.Run(name+" error", func(t *testing.T) {
+			assert.ErrorIs(t, tc.zip(failing()), errBoom)
+		})
+
+		t.Run(name+" panic", func(t *testing.T) {
+			var pe *PanicError
+			if assert.ErrorAs(t, tc.zip(panicking()), &pe) {
+				assert.Equal(t, "boom", pe.Value)
+			}
+		})
+	}
+}
+
+func TestZipLongest(t *testing.T) {
+	tt := map[string]struct {
+		a    Stream[int]
+		b    Stream[string]
+		want []Tuple2[Optional[int], Optional[string]]
+	}{
+		"Should pad the second Stream": {
+			a: NewStreamFromSlice([]int{1, 2, 3}, 0),
+			b: NewStreamFromSlice([]string{"a"}, 0),
+			want: []Tuple2[Optional[int], Optional[string]]{
+				{OptionalOf(1), OptionalOf("a")},
+				{OptionalOf(2), OptionalEmpty[string]()},
+				{OptionalOf(3), OptionalEmpty[string]()},
+			},
+		},
+		"Should pad the first Stream": {
+			a: NewStreamFromSlice([]int{1}, 0).Filter(True[int]()),
+			b: NewStreamFromSlice([]string{"a", "b"}, 0),
+			want: []Tuple2[Optional[int], Optional[string]]{
+				{OptionalOf(1), OptionalOf("a")},
+				{OptionalEmpty[int](), OptionalOf("b")},
+			},
+		},
+		"Should pad a nil channel": {
+			a: Stream[int]{},
+			b: NewStreamFromSlice([]string{"a"}, 0),
+			want: []Tuple2[Optional[int], Optional[string]]{
+				{OptionalEmpty[int](), OptionalOf("a")},
+			},
+		},
+		"Should return an empty Stream when both Streams are empty": {
+			a:    NewStreamFromSlice([]int{}, 0),
+			b:    Stream[string]{},
+			want: []Tuple2[Optional[int], Optional[string]]{},
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ZipLongest(tc.a, tc.b).ToSlice())
+		})
+	}
+
+	assert.Len(t, ZipLongest(NewStreamFromSlice([]int{1, 2, 3}, 0), NewStreamFromSlice([]string{"a"}, 0)).HeadN(2), 2)
+}
+
+func TestUnzip(t *testing.T) {
+	pairs := Zip(
+		NewStreamFromSlice([]int{1, 2, 3}, 0),
+		NewStreamFromSlice([]string{"a", "b", "c"}, 0),
+	)
+
+	ints, strs := Unzip(pairs)
+
+	var gotStrs []string
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		gotStrs = strs.ToSlice()
+	}()
+
+	assert.Equal(t, []int{1, 2, 3}, ints.ToSlice())
+	<-done
+	assert.Equal(t, []string{"a", "b", "c"}, gotStrs)
+}
+
+func TestUnzip_ConsumedOneAfterTheOther(t *testing.T) {
+	pairs := Zip(
+		FromSlice([]int{1, 2, 3, 4, 5, 6}),
+		FromSlice([]string{"a", "b", "c", "d", "e", "f"}),
+	)
+
+	ints, strs := Unzip(pairs)
+
+	// the Streams are consumed one after the other.
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ints.ToSlice())
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, strs.ToSlice())
+}
+
+func TestUnzip_OneSideStops(t *testing.T) {
+	pairs := Zip(
+		NewStreamFromSlice([]int{1, 2, 3, 4}, 0),
+		NewStreamFromSlice([]string{"a", "b", "c", "d"}, 0),
+	)
+
+	ints, strs := Unzip(pairs)
+
+	var gotStrs []string
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		gotStrs = strs.HeadN(1)
+	}()
+
+	assert.Equal(t, []int{1, 2, 3, 4}, ints.ToSlice())
+	<-done
+	assert.Equal(t, []string{"a"}, gotStrs)
+}